@@ -0,0 +1,141 @@
+// src/api.ts
+// Public extension API for custom context providers & theme change observers
+
+import * as vscode from "vscode";
+import { getActiveRuleSet, loadConfig, setActiveProfile } from "./config";
+import { ContextManager } from "./contextManager";
+import { ThemeApplyEvent, ThemeApplyStats, ThemeManager } from "./themeManager";
+import type { ThemeDecision } from "./commands/explainTheme";
+
+// provider contributed by another extension; rules match its value via when.custom[id]
+export interface ContextProvider {
+    // current value, or undefined when the provider has nothing to report
+    getValue(): string | undefined;
+    // fires w/ the new value whenever it changes
+    readonly onDidChange: vscode.Event<string | undefined>;
+}
+
+// API returned from activate(), available via vscode.extensions.getExtension(...).exports
+export interface ReactiveThemesApi {
+    // register a provider under a unique id; dispose to unregister & clear its value
+    registerContextProvider(id: string, provider: ContextProvider): vscode.Disposable;
+    // ids of currently registered providers
+    getContextProviderIds(): string[];
+    // fires after every attempted theme change, w/ reason & apply duration
+    readonly onDidApplyTheme: vscode.Event<ThemeApplyEvent>;
+    // applied/failed counts & last apply duration since activation
+    getApplyStats(): ThemeApplyStats;
+    // recent theme changes, newest first
+    getThemeHistory(): ThemeApplyEvent[];
+    // theme restored when switching is disabled (persisted across sessions)
+    getOriginalTheme(): string | undefined;
+    setOriginalTheme(theme: string): Promise<void>;
+    // undo the last theme change; resolves to the restored theme, if any
+    revertToPreviousTheme(): Promise<string | undefined>;
+    // theme the user picked manually while switching is paused, if any
+    getManualOverride(): string | undefined;
+    // clear a sticky manual override; resolves true if one was active
+    resumeAutomaticSwitching(): Promise<boolean>;
+    // which source (override, manual, rule, default, original) decided the current theme
+    explainCurrentTheme(): ThemeDecision | undefined;
+    // names defined in reactiveThemes.profiles
+    getProfiles(): string[];
+    getActiveProfile(): string | undefined;
+    // activate a profile by name (undefined returns to top-level rules); rejects unknown names
+    switchProfile(name: string | undefined): Promise<void>;
+}
+
+const PROVIDER_ID_PATTERN = /^[A-Za-z0-9][\w.-]*$/;
+
+// * create extension API backed by the context & theme managers
+export function createExtensionApi(
+    contextManager: ContextManager,
+    themeManager: ThemeManager,
+    explain?: () => ThemeDecision
+): ReactiveThemesApi {
+    const providers = new Map<string, vscode.Disposable>();
+
+    return {
+        registerContextProvider(id: string, provider: ContextProvider): vscode.Disposable {
+            if (!PROVIDER_ID_PATTERN.test(id)) {
+                throw new Error(
+                    `Invalid context provider id "${id}" (use letters, digits, ".", "_", or "-")`
+                );
+            }
+            if (providers.has(id)) {
+                throw new Error(`Context provider "${id}" is already registered`);
+            }
+
+            const listener = provider.onDidChange((value) => {
+                contextManager.setCustomValue(id, value);
+            });
+            const registration = new vscode.Disposable(() => {
+                if (providers.get(id) !== registration) {
+                    return;
+                }
+                listener.dispose();
+                providers.delete(id);
+                contextManager.setCustomValue(id, undefined);
+            });
+
+            providers.set(id, registration);
+            contextManager.setCustomValue(id, provider.getValue());
+            return registration;
+        },
+
+        getContextProviderIds(): string[] {
+            return Array.from(providers.keys());
+        },
+
+        onDidApplyTheme: themeManager.onDidApplyTheme,
+
+        getApplyStats(): ThemeApplyStats {
+            return themeManager.getApplyStats();
+        },
+
+        getThemeHistory(): ThemeApplyEvent[] {
+            return themeManager.getHistory();
+        },
+
+        getOriginalTheme(): string | undefined {
+            return themeManager.getOriginalTheme();
+        },
+
+        setOriginalTheme(theme: string): Promise<void> {
+            return themeManager.setOriginalTheme(theme);
+        },
+
+        revertToPreviousTheme(): Promise<string | undefined> {
+            return themeManager.revertToPreviousTheme();
+        },
+
+        getManualOverride(): string | undefined {
+            return themeManager.getManualOverride();
+        },
+
+        async resumeAutomaticSwitching(): Promise<boolean> {
+            if (!themeManager.getManualOverride()) {
+                return false;
+            }
+            // route through the command so the active editor is re-evaluated
+            await vscode.commands.executeCommand("reactiveThemes.resumeAutomaticSwitching");
+            return true;
+        },
+
+        explainCurrentTheme(): ThemeDecision | undefined {
+            return explain?.();
+        },
+
+        getProfiles(): string[] {
+            return Object.keys(loadConfig().profiles ?? {});
+        },
+
+        getActiveProfile(): string | undefined {
+            return getActiveRuleSet(loadConfig()).profile;
+        },
+
+        switchProfile(name: string | undefined): Promise<void> {
+            return setActiveProfile(name);
+        },
+    };
+}
@@ -3,7 +3,7 @@
 
 import * as vscode from "vscode";
 import { ThemeRule } from "../types";
-import { loadConfig } from "../config";
+import { getActiveRuleSet, loadConfig } from "../config";
 import { lintRules, LintIssue } from "../ruleLinter";
 import { confirmDeleteRule as confirmDeleteRulePrompt, deleteRules } from "../utils/ruleOperations";
 import { handleOperationError } from "../utils/errorHandling";
@@ -13,15 +13,15 @@ import { confirmAction, showLintIssuePicker } from "./uiHelpers";
 export async function cleanupDuplicateRules(): Promise<void> {
     console.log("[Reactive Themes] Cleaning up duplicate rules");
 
-    const config = loadConfig();
+    const { rules } = getActiveRuleSet(loadConfig());
 
-    if (config.rules.length === 0) {
+    if (rules.length === 0) {
         vscode.window.showInformationMessage("No rules configured yet.");
         return;
     }
 
     // reuse the linter so overlap/duplicate logic stays centralized
-    const lintResult = await lintRules(config.rules);
+    const lintResult = await lintRules(rules);
     const cleanupCandidates = lintResult.issues.filter(
         (issue) => issue.type === "duplicate" || issue.type === "unreachable"
     );
@@ -31,7 +31,7 @@ export async function cleanupDuplicateRules(): Promise<void> {
         return;
     }
 
-    await reviewLintIssues(config.rules, cleanupCandidates);
+    await reviewLintIssues(rules, cleanupCandidates);
 }
 
 async function reviewLintIssues(rules: ThemeRule[], issues: LintIssue[]): Promise<void> {
@@ -4,7 +4,7 @@
 import * as vscode from "vscode";
 import * as path from "path";
 import { ThemeRule } from "../types";
-import { saveRule, loadConfig, updateRule } from "../config";
+import { saveRule, loadConfig, updateRule, getActiveRuleSet } from "../config";
 import { findOverlappingRules } from "../ruleOverlap";
 import { selectTheme, confirmAction } from "./uiHelpers";
 import { validateRuleName } from "../utils/validators";
@@ -143,9 +143,9 @@ async function confirmAndSaveRule(
         theme: theme.name,
     };
 
-    // check for overlapping rules
-    const config = loadConfig();
-    const overlappingRules = findOverlappingRules(rule, config.rules);
+    // check for overlapping rules in the rule set the new rule is saved to
+    const { rules } = getActiveRuleSet(loadConfig());
+    const overlappingRules = findOverlappingRules(rule, rules);
 
     if (overlappingRules.length > 0) {
         // show warning about overlapping rules
@@ -189,7 +189,7 @@ async function confirmAndSaveRule(
             }
 
             // find index and replace
-            const indexToReplace = config.rules.findIndex((r) => r === ruleToReplace);
+            const indexToReplace = rules.findIndex((r) => r === ruleToReplace);
             if (indexToReplace !== -1) {
                 try {
                     await updateRule(indexToReplace, rule);
@@ -2,22 +2,33 @@
 // Explains current theme selection w/ winning rule, shadowed rules, & context snapshot
 
 import * as vscode from "vscode";
-import { loadConfig, getCurrentTheme } from "../config";
-import { getRuleMatchDetails, MatchOptions, extractFileContext } from "../ruleEngine";
+import {
+    EnvironmentOverride,
+    loadConfig,
+    getActiveRuleSet,
+    getCurrentTheme,
+    resolvePrecedence,
+} from "../config";
+import { getRuleMatchDetails, MatchOptions, extractFileContext, FileContext } from "../ruleEngine";
+import { isWellFormedRule } from "../ruleOverlap";
 import { ThemeManager } from "../themeManager";
 import { Context, ContextFlags, ContextManager } from "../contextManager";
 import { TimerTrigger } from "../triggers/timerTrigger";
+import { SolarTrigger } from "../triggers/solarTrigger";
 import type { ThemeRule } from "../types";
 import { getSharedOutputChannel } from "./uiHelpers";
 import { formatRuleConditionsDetailed } from "../utils/ruleFormatters";
+import { SolarTransition, describeSolarTransition } from "../utils/solar";
 
 export interface ExplainThemeDependencies {
     themeManager?: ThemeManager;
     contextManager?: ContextManager;
     timerTrigger?: TimerTrigger;
+    solarTrigger?: SolarTrigger;
+    environmentOverride?: EnvironmentOverride;
 }
 
-interface RuleEvaluation {
+export interface RuleEvaluation {
     rule: ThemeRule;
     index: number;
     matched: boolean;
@@ -25,18 +36,43 @@ interface RuleEvaluation {
     conditions: string[];
 }
 
+export type ThemeSource = "rule" | "default" | "manual" | "original" | "override";
+
+// condensed explanation exposed through the extension API
+export interface ThemeDecision {
+    theme: string;
+    source: ThemeSource;
+    // winning rule, only set when source is "rule"
+    rule?: ThemeRule;
+    ruleIndex?: number;
+    profile?: string;
+    summary: string;
+}
+
 interface ThemeExplanation {
     currentTheme: string;
     appliedTheme?: string;
     originalTheme?: string;
     defaultTheme?: string;
-    themeSource: "rule" | "default" | "manual" | "original";
+    profile?: string;
+    rulesSetting: string;
+    themeSource: ThemeSource;
     fileContext: {
         languageId?: string;
         filePath?: string;
         workspaceName?: string;
     };
-    environmentContext: Pick<ContextFlags, "debugSession" | "debugType" | "testState" | "viewMode">;
+    environmentContext: Pick<
+        ContextFlags,
+        | "debugSession"
+        | "debugType"
+        | "testState"
+        | "viewMode"
+        | "timeOfDay"
+        | "activeSchedules"
+        | "custom"
+    >;
+    nextSolarTransition?: SolarTransition;
     gitContext: {
         branch?: string;
         status?: "clean" | "dirty";
@@ -157,6 +193,14 @@ function buildWhySummary(
     }
 }
 
+// custom provider values as "id=value" pairs, or undefined when none are reported
+function formatCustomContext(custom: Record<string, string> | undefined): string | undefined {
+    const entries = Object.entries(custom ?? {});
+    return entries.length > 0
+        ? entries.map(([id, value]) => `${id}=${value}`).join(", ")
+        : undefined;
+}
+
 function buildMatchOptions(timerTrigger?: TimerTrigger): MatchOptions | undefined {
     if (!timerTrigger) {
         return undefined;
@@ -173,6 +217,61 @@ function buildMatchOptions(timerTrigger?: TimerTrigger): MatchOptions | undefine
     };
 }
 
+// placeholder shown for a settings entry that isn't a valid rule
+function describeMalformedRule(rule: unknown, index: number): ThemeRule {
+    const entry = (rule && typeof rule === "object" ? rule : {}) as Partial<ThemeRule>;
+    return {
+        name: typeof entry.name === "string" ? entry.name : `Malformed rule #${index + 1}`,
+        when: {},
+        theme: typeof entry.theme === "string" ? entry.theme : "(none)",
+    };
+}
+
+// * Evaluates rules in priority order; the first match wins & later matches are shadowed
+export function evaluateRulesForExplanation(
+    rules: ThemeRule[],
+    fileContext: FileContext,
+    ctx: Context,
+    matchOptions: MatchOptions = {}
+): { evaluations: RuleEvaluation[]; winnerIndex?: number } {
+    const evaluations: RuleEvaluation[] = [];
+    let winnerIndex: number | undefined = undefined;
+
+    for (let i = 0; i < rules.length; i++) {
+        const rule = rules[i];
+
+        // ? malformed entries keep their slot so indices line up w/ settings, but never match
+        if (!isWellFormedRule(rule)) {
+            evaluations.push({
+                rule: describeMalformedRule(rule, i),
+                index: i,
+                matched: false,
+                reasons: ["✗ Malformed rule: see configuration errors in the developer console"],
+                conditions: [],
+            });
+            continue;
+        }
+
+        const { matched, reasons } = getRuleMatchDetails(rule, fileContext, ctx, matchOptions, i);
+        const conditions = formatRuleConditionsDetailed(rule);
+
+        evaluations.push({
+            rule,
+            index: i,
+            matched,
+            reasons,
+            conditions,
+        });
+
+        // First-match-wins: record first matching rule
+        if (matched && winnerIndex === undefined) {
+            winnerIndex = i;
+        }
+    }
+
+    return { evaluations, winnerIndex };
+}
+
 // * Collects all theme evaluation data for current context
 function gatherThemeExplanation(dependencies: ExplainThemeDependencies): ThemeExplanation {
     const config = loadConfig();
@@ -191,13 +290,18 @@ function gatherThemeExplanation(dependencies: ExplainThemeDependencies): ThemeEx
         debugType: ctx.debugType,
         testState: ctx.testState,
         viewMode: ctx.viewMode,
+        timeOfDay: ctx.timeOfDay,
+        activeSchedules: ctx.activeSchedules,
+        custom: ctx.custom,
     };
+    const nextSolarTransition = dependencies.solarTrigger?.getNextTransition();
 
     // Get theme information
     const currentTheme = getCurrentTheme();
     const appliedTheme = themeManager?.getCurrentAppliedTheme();
     const originalTheme = themeManager?.getOriginalTheme();
-    const defaultTheme = config.defaultTheme;
+    const ruleSet = getActiveRuleSet(config);
+    const defaultTheme = ruleSet.defaultTheme;
 
     // Get git context (placeholder for now)
     const gitContext = getGitContext();
@@ -205,49 +309,54 @@ function gatherThemeExplanation(dependencies: ExplainThemeDependencies): ThemeEx
     const matchOptions = buildMatchOptions(dependencies.timerTrigger);
 
     // Evaluate all rules to find winner, shadowed, & non-matching
-    const rules = config.rules;
-    const evaluations: RuleEvaluation[] = [];
-    let winnerIndex: number | undefined = undefined;
-
-    for (let i = 0; i < rules.length; i++) {
-        const rule = rules[i];
-        const { matched, reasons } = getRuleMatchDetails(rule, fileContext, ctx, matchOptions, i);
-        const conditions = formatRuleConditionsDetailed(rule);
-
-        evaluations.push({
-            rule,
-            index: i,
-            matched,
-            reasons,
-            conditions,
-        });
-
-        // First-match-wins: record first matching rule
-        if (matched && winnerIndex === undefined) {
-            winnerIndex = i;
-        }
-    }
+    const { evaluations, winnerIndex } = evaluateRulesForExplanation(
+        ruleSet.rules,
+        fileContext,
+        ctx,
+        matchOptions
+    );
 
     // Determine theme source
-    const themeSource = determineThemeSource(
-        appliedTheme,
-        winnerIndex,
-        defaultTheme,
-        currentTheme,
-        originalTheme
-    );
+    // precedence: reactiveThemes.precedence order, then default > original
+    const override = dependencies.environmentOverride;
+    const manualOverride = themeManager?.getManualOverride();
+    const winningSource = resolvePrecedence(config, {
+        environment: override !== undefined,
+        manual: manualOverride !== undefined,
+        rules: winnerIndex !== undefined,
+    });
+    const themeSource =
+        winningSource === "environment"
+            ? "override"
+            : winningSource === "manual"
+              ? "manual"
+              : determineThemeSource(
+                    appliedTheme,
+                    winnerIndex,
+                    defaultTheme,
+                    currentTheme,
+                    originalTheme
+                );
 
     // Build why summary
-    const whySummary = buildWhySummary(winnerIndex, evaluations, defaultTheme, currentTheme);
+    const whySummary =
+        winningSource === "environment" && override
+            ? `${override.source} forces theme "${override.theme}" → rules are not applied`
+            : winningSource === "manual" && manualOverride
+              ? `Manual theme change to "${manualOverride}" → automatic switching paused (run "Resume Automatic Theme Switching")`
+              : buildWhySummary(winnerIndex, evaluations, defaultTheme, currentTheme);
 
     return {
         currentTheme,
         appliedTheme,
         originalTheme,
         defaultTheme,
+        profile: ruleSet.profile,
+        rulesSetting: ruleSet.setting,
         themeSource,
         fileContext,
         environmentContext,
+        nextSolarTransition,
         gitContext,
         evaluations,
         winnerIndex,
@@ -255,6 +364,24 @@ function gatherThemeExplanation(dependencies: ExplainThemeDependencies): ThemeEx
     };
 }
 
+// * summarize which source won for the current context
+export function explainThemeDecision(dependencies: ExplainThemeDependencies): ThemeDecision {
+    const explanation = gatherThemeExplanation(dependencies);
+    const winner =
+        explanation.themeSource === "rule" && explanation.winnerIndex !== undefined
+            ? explanation.evaluations[explanation.winnerIndex]
+            : undefined;
+
+    return {
+        theme: explanation.currentTheme,
+        source: explanation.themeSource,
+        rule: winner?.rule,
+        ruleIndex: winner?.index,
+        profile: explanation.profile,
+        summary: explanation.whySummary,
+    };
+}
+
 interface EvaluationBuckets {
     winner?: RuleEvaluation;
     shadowedRules: RuleEvaluation[];
@@ -318,7 +445,7 @@ async function displayExplanation(explanation: ThemeExplanation): Promise<void>
 
         outputChannel.appendLine(`Rule:     ${evaluation.rule.name} (#${evaluation.index + 1})`);
         outputChannel.appendLine(
-            `Source:   settings.json → reactiveThemes.rules[${evaluation.index}]`
+            `Source:   settings.json → ${explanation.rulesSetting}[${evaluation.index}]`
         );
         outputChannel.appendLine(`Theme:    ${evaluation.rule.theme}`);
         outputChannel.appendLine(`Matched:  ${evaluation.matched ? "yes" : "no"}`);
@@ -418,6 +545,26 @@ function buildQuickPickItems(
         });
     }
 
+    if (environmentContext.timeOfDay) {
+        items.push({
+            label: `  Time of Day: ${environmentContext.timeOfDay}`,
+            description: explanation.nextSolarTransition
+                ? `(${describeSolarTransition(explanation.nextSolarTransition)})`
+                : undefined,
+        });
+    }
+
+    if (environmentContext.activeSchedules && environmentContext.activeSchedules.length > 0) {
+        items.push({
+            label: `  Active Schedules: ${environmentContext.activeSchedules.join(", ")}`,
+        });
+    }
+
+    const customSummary = formatCustomContext(environmentContext.custom);
+    if (customSummary) {
+        items.push({ label: `  Custom: ${customSummary}` });
+    }
+
     // Section: Winning Rule
     if (winner) {
         items.push({
@@ -522,6 +669,9 @@ function logDetailedExplanation(
     if (explanation.defaultTheme) {
         channel.appendLine(`│  Default Theme:  ${explanation.defaultTheme}`);
     }
+    if (explanation.profile) {
+        channel.appendLine(`│  Profile:        ${explanation.profile}`);
+    }
     channel.appendLine("└─");
     channel.appendLine("");
 
@@ -545,7 +695,10 @@ function logDetailedExplanation(
     if (
         environmentContext.debugSession ||
         environmentContext.testState ||
-        environmentContext.viewMode
+        environmentContext.viewMode ||
+        environmentContext.timeOfDay ||
+        (environmentContext.activeSchedules && environmentContext.activeSchedules.length > 0) ||
+        formatCustomContext(environmentContext.custom)
     ) {
         channel.appendLine("│  Environment Context:");
         if (environmentContext.debugSession) {
@@ -560,6 +713,21 @@ function logDetailedExplanation(
         if (environmentContext.viewMode) {
             channel.appendLine(`│    View:       ${environmentContext.viewMode}`);
         }
+        if (environmentContext.timeOfDay) {
+            const nextInfo = explanation.nextSolarTransition
+                ? ` (${describeSolarTransition(explanation.nextSolarTransition)})`
+                : "";
+            channel.appendLine(`│    Time:       ${environmentContext.timeOfDay}${nextInfo}`);
+        }
+        if (environmentContext.activeSchedules && environmentContext.activeSchedules.length > 0) {
+            channel.appendLine(
+                `│    Schedules:  ${environmentContext.activeSchedules.join(", ")}`
+            );
+        }
+        const customSummary = formatCustomContext(environmentContext.custom);
+        if (customSummary) {
+            channel.appendLine(`│    Custom:     ${customSummary}`);
+        }
     }
 
     // Git context (placeholder for future)
@@ -595,7 +763,9 @@ function logDetailedExplanation(
     if (winner) {
         channel.appendLine("┌─ ★ WINNING RULE (First Match)");
         channel.appendLine(`│  Rule:     ${winner.rule.name} (#${winner.index + 1})`);
-        channel.appendLine(`│  Source:   settings.json → reactiveThemes.rules[${winner.index}]`);
+        channel.appendLine(
+            `│  Source:   settings.json → ${explanation.rulesSetting}[${winner.index}]`
+        );
         channel.appendLine(`│  Theme:    ${winner.rule.theme}`);
         if (winner.conditions.length > 0) {
             channel.appendLine("│  Conditions:");
@@ -630,7 +800,7 @@ function logDetailedExplanation(
             }
             channel.appendLine(`│  Rule:     ${shadowed.rule.name} (#${shadowed.index + 1})`);
             channel.appendLine(
-                `│  Source:   settings.json → reactiveThemes.rules[${shadowed.index}]`
+                `│  Source:   settings.json → ${explanation.rulesSetting}[${shadowed.index}]`
             );
             channel.appendLine(`│  Theme:    ${shadowed.rule.theme}`);
             channel.appendLine(
@@ -660,7 +830,7 @@ function logDetailedExplanation(
             }
             channel.appendLine(`│  Rule:     ${nonMatch.rule.name} (#${nonMatch.index + 1})`);
             channel.appendLine(
-                `│  Source:   settings.json → reactiveThemes.rules[${nonMatch.index}]`
+                `│  Source:   settings.json → ${explanation.rulesSetting}[${nonMatch.index}]`
             );
             channel.appendLine(`│  Theme:    ${nonMatch.rule.theme}`);
             if (nonMatch.conditions.length > 0) {
@@ -706,6 +876,9 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
     if (explanation.defaultTheme) {
         md += `- **Default Theme:** ${explanation.defaultTheme}\n`;
     }
+    if (explanation.profile) {
+        md += `- **Profile:** ${explanation.profile}\n`;
+    }
     md += "\n";
 
     // Context Snapshot
@@ -730,7 +903,10 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
     if (
         environmentContext.debugSession ||
         environmentContext.testState ||
-        environmentContext.viewMode
+        environmentContext.viewMode ||
+        environmentContext.timeOfDay ||
+        (environmentContext.activeSchedules && environmentContext.activeSchedules.length > 0) ||
+        formatCustomContext(environmentContext.custom)
     ) {
         md += `### Environment Context\n\n`;
         if (environmentContext.debugSession) {
@@ -745,6 +921,19 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
         if (environmentContext.viewMode) {
             md += `- **View:** ${environmentContext.viewMode}\n`;
         }
+        if (environmentContext.timeOfDay) {
+            const nextInfo = explanation.nextSolarTransition
+                ? ` (${describeSolarTransition(explanation.nextSolarTransition)})`
+                : "";
+            md += `- **Time of Day:** ${environmentContext.timeOfDay}${nextInfo}\n`;
+        }
+        if (environmentContext.activeSchedules && environmentContext.activeSchedules.length > 0) {
+            md += `- **Active Schedules:** ${environmentContext.activeSchedules.join(", ")}\n`;
+        }
+        const customSummary = formatCustomContext(environmentContext.custom);
+        if (customSummary) {
+            md += `- **Custom:** ${customSummary}\n`;
+        }
         md += "\n";
     }
 
@@ -764,7 +953,7 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
     if (winner) {
         md += `### ★ Winning Rule (First Match)\n\n`;
         md += `- **Rule:** ${winner.rule.name} (#${winner.index + 1})\n`;
-        md += `- **Source:** \`settings.json → reactiveThemes.rules[${winner.index}]\`\n`;
+        md += `- **Source:** \`settings.json → ${explanation.rulesSetting}[${winner.index}]\`\n`;
         md += `- **Theme:** ${winner.rule.theme}\n\n`;
 
         if (winner.conditions.length > 0) {
@@ -795,7 +984,7 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
         md += `### ⚠ Shadowed Rules (Matched but Lost to Priority)\n\n`;
         shadowedRules.forEach((shadowed) => {
             md += `#### ${shadowed.rule.name} (#${shadowed.index + 1})\n\n`;
-            md += `- **Source:** \`settings.json → reactiveThemes.rules[${shadowed.index}]\`\n`;
+            md += `- **Source:** \`settings.json → ${explanation.rulesSetting}[${shadowed.index}]\`\n`;
             md += `- **Theme:** ${shadowed.rule.theme}\n`;
             md += `- **Why Lost:** Lower priority (first-match-wins) - rule #${winner ? winner.index + 1 : "?"} matched first\n\n`;
 
@@ -820,7 +1009,7 @@ function formatExplanationAsMarkdown(explanation: ThemeExplanation): string {
         md += `### Non-Matching Rules\n\n`;
         nonMatchingRules.forEach((nonMatch) => {
             md += `#### ${nonMatch.rule.name} (#${nonMatch.index + 1})\n\n`;
-            md += `- **Source:** \`settings.json → reactiveThemes.rules[${nonMatch.index}]\`\n`;
+            md += `- **Source:** \`settings.json → ${explanation.rulesSetting}[${nonMatch.index}]\`\n`;
             md += `- **Theme:** ${nonMatch.rule.theme}\n\n`;
 
             if (nonMatch.conditions.length > 0) {
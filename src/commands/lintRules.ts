@@ -3,7 +3,7 @@
 
 import * as vscode from "vscode";
 import { ThemeRule } from "../types";
-import { loadConfig, deleteRule, refreshConfig } from "../config";
+import { loadConfig, deleteRule, refreshConfig, getActiveRuleSet } from "../config";
 import {
     lintRules,
     groupIssuesByType,
@@ -30,9 +30,10 @@ export async function lintRulesCommand(): Promise<void> {
         async () => {
             console.log("[Reactive Themes] Linting rules");
 
-            const config = loadConfig();
+            // lint the rules in effect; fixes write back to the same setting
+            const { rules } = getActiveRuleSet(loadConfig());
 
-            if (config.rules.length === 0) {
+            if (rules.length === 0) {
                 vscode.window.showInformationMessage("No rules to lint.");
                 return;
             }
@@ -41,10 +42,10 @@ export async function lintRulesCommand(): Promise<void> {
             const outputChannel = getSharedOutputChannel();
 
             // run linting
-            const lintResult = await lintRules(config.rules);
+            const lintResult = await lintRules(rules);
 
             // show detailed results in output channel
-            logLintResults(outputChannel, lintResult, config.rules);
+            logLintResults(outputChannel, lintResult, rules);
 
             if (lintResult.issues.length === 0) {
                 vscode.window.showInformationMessage("✓ No issues found! All rules are valid.");
@@ -52,7 +53,7 @@ export async function lintRulesCommand(): Promise<void> {
             }
 
             // show summary and let user choose what to do
-            await showLintSummary(lintResult, config.rules, outputChannel);
+            await showLintSummary(lintResult, rules, outputChannel);
         },
         { showUser: true, rethrow: false }
     );
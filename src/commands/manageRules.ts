@@ -3,16 +3,19 @@
 
 import * as vscode from "vscode";
 import { ThemeRule } from "../types";
-import { loadConfig, updateRule, deleteRule } from "../config";
+import { loadConfig, updateRule, deleteRule, getActiveRuleSet } from "../config";
 import { buildOverlapMap } from "../ruleOverlap";
 import { selectTheme, selectRule } from "./uiHelpers";
 import { confirmDeleteRule as confirmDeleteRulePrompt } from "../utils/ruleOperations";
+import { validateCronExpression } from "../utils/cron";
 import {
     validateDebugType,
     validateGlobPattern,
     validateLanguageId,
     validateWorkspaceName,
     validateTimerInterval,
+    parseCustomConditions,
+    validateCustomConditions,
     validateRuleName,
 } from "../utils/validators";
 import { formatRuleConditions } from "../utils/ruleFormatters";
@@ -25,9 +28,10 @@ export async function manageRules(): Promise<void> {
         async () => {
             console.log("[Reactive Themes] Managing rules");
 
-            const config = loadConfig();
+            // edits write back to the active profile's rules when it has its own
+            const { rules, profile, setting } = getActiveRuleSet(loadConfig());
 
-            if (config.rules.length === 0) {
+            if (rules.length === 0) {
                 const createNew = await vscode.window.showInformationMessage(
                     "No rules configured yet.",
                     "Create First Rule"
@@ -40,11 +44,14 @@ export async function manageRules(): Promise<void> {
             }
 
             // show list of rules
-            const overlapsMap = buildOverlapMap(config.rules);
-            const selectedRuleIndex = await selectRule(config.rules, {
+            const overlapsMap = buildOverlapMap(rules);
+            const selectedRuleIndex = await selectRule(rules, {
                 overlapsMap,
                 includeFindDuplicatesItem: overlapsMap.size > 0,
-                title: "Manage Rules - Select a Rule",
+                title:
+                    profile && setting !== "reactiveThemes.rules"
+                        ? `Manage Rules - Select a Rule (profile "${profile}")`
+                        : "Manage Rules - Select a Rule",
                 placeHolder: "Choose a rule to manage",
             });
             if (selectedRuleIndex === undefined) {
@@ -52,12 +59,12 @@ export async function manageRules(): Promise<void> {
             }
 
             if (selectedRuleIndex === -1) {
-                await showDuplicateRules(config.rules, overlapsMap);
+                await showDuplicateRules(rules, overlapsMap);
                 return;
             }
 
             // show actions for selected rule
-            await showRuleActions(selectedRuleIndex, config.rules[selectedRuleIndex]);
+            await showRuleActions(selectedRuleIndex, rules[selectedRuleIndex]);
         },
         { showUser: true, rethrow: false }
     );
@@ -174,6 +181,10 @@ async function editMatchConditions(index: number, rule: ThemeRule): Promise<void
     const hasDebugType = rule.when.debugType !== undefined;
     const hasTestState = rule.when.testState !== undefined;
     const hasViewMode = rule.when.viewMode !== undefined;
+    const hasTimeOfDay = rule.when.timeOfDay !== undefined;
+    const hasSchedule = rule.when.schedule !== undefined;
+    const customEntries = Object.entries(rule.when.custom ?? {});
+    const currentCustom = customEntries.map(([id, value]) => `${id}=${value}`).join(", ");
     const hasTimer = rule.when.timerInterval !== undefined;
 
     const options: Array<
@@ -186,6 +197,9 @@ async function editMatchConditions(index: number, rule: ThemeRule): Promise<void
                 | "debugType"
                 | "testState"
                 | "viewMode"
+                | "timeOfDay"
+                | "schedule"
+                | "custom"
                 | "timerInterval";
         }
     > = [
@@ -224,6 +238,21 @@ async function editMatchConditions(index: number, rule: ThemeRule): Promise<void
             description: hasViewMode ? `Current: ${rule.when.viewMode}` : "Any",
             type: "viewMode",
         },
+        {
+            label: "$(globe) Time of Day",
+            description: hasTimeOfDay ? `Current: ${rule.when.timeOfDay}` : "Any",
+            type: "timeOfDay",
+        },
+        {
+            label: "$(calendar) Schedule (cron)",
+            description: hasSchedule ? `Current: ${rule.when.schedule}` : "Not set",
+            type: "schedule",
+        },
+        {
+            label: "$(extensions) Custom Context",
+            description: currentCustom ? `Current: ${currentCustom}` : "Not set",
+            type: "custom",
+        },
         {
             label: "$(clock) Timer Interval (minutes)",
             description: hasTimer ? `Current: ${rule.when.timerInterval}m` : "Not set",
@@ -379,6 +408,41 @@ async function editMatchConditions(index: number, rule: ThemeRule): Promise<void
             return;
         }
         updatedRule.when.viewMode = choice.value;
+    } else if (selected.type === "timeOfDay") {
+        const choice = await vscode.window.showQuickPick(
+            [
+                { label: "Any (clear time of day)", value: undefined },
+                { label: "Day", value: "day" as const },
+                { label: "Night", value: "night" as const },
+            ],
+            { title: "Time of day (requires reactiveThemes.location)" }
+        );
+        if (!choice) {
+            return;
+        }
+        updatedRule.when.timeOfDay = choice.value;
+    } else if (selected.type === "schedule") {
+        const newValue = await vscode.window.showInputBox({
+            prompt: "Enter cron expression (minute hour day month weekday) or leave blank to clear",
+            placeHolder: "* 9-16 * * 1-5",
+            value: rule.when.schedule,
+            validateInput: (value) => (value?.trim() ? validateCronExpression(value) : undefined),
+        });
+        if (newValue === undefined) {
+            return;
+        }
+        updatedRule.when.schedule = newValue.trim() || undefined;
+    } else if (selected.type === "custom") {
+        const newValue = await vscode.window.showInputBox({
+            prompt: "Enter provider values as id=value pairs, comma separated (leave blank to clear)",
+            placeHolder: "ci.status=failing, focus.app=slack",
+            value: currentCustom,
+            validateInput: (value) => (value?.trim() ? validateCustomConditions(value) : undefined),
+        });
+        if (newValue === undefined) {
+            return;
+        }
+        updatedRule.when.custom = newValue.trim() ? parseCustomConditions(newValue) : undefined;
     } else if (selected.type === "timerInterval") {
         const newValue = await vscode.window.showInputBox({
             prompt: "Enter timer interval in minutes (leave blank to clear)",
@@ -0,0 +1,77 @@
+// src/commands/switchProfile.ts
+// Quick pick for switching between named rule profiles at runtime
+
+import * as vscode from "vscode";
+import { loadConfig, setActiveProfile } from "../config";
+import { ThemeProfile } from "../types";
+
+type ProfileQuickPickItem = vscode.QuickPickItem & { profile?: string };
+
+// short summary of what a profile changes
+function describeProfile(profile: ThemeProfile): string {
+    const parts: string[] = [];
+    if (Array.isArray(profile.rules)) {
+        parts.push(`${profile.rules.length} rule(s)`);
+    }
+    if (profile.defaultTheme) {
+        parts.push(`default: ${profile.defaultTheme}`);
+    }
+    return parts.join(" · ") || "uses top-level rules & default theme";
+}
+
+// * switch profile by name, or prompt when no name is given (e.g. from a keybinding w/ args)
+export async function switchProfile(name?: string): Promise<void> {
+    const config = loadConfig();
+    const profiles = config.profiles ?? {};
+    const names = Object.keys(profiles);
+
+    let selected: string | undefined = name;
+    if (selected === undefined) {
+        if (names.length === 0) {
+            vscode.window.showInformationMessage(
+                "Reactive Themes: No profiles defined. Add some under reactiveThemes.profiles."
+            );
+            return;
+        }
+
+        const items: ProfileQuickPickItem[] = [
+            {
+                label: `${config.activeProfile ? "" : "$(check) "}(no profile)`,
+                description: "Use reactiveThemes.rules",
+            },
+            ...names.map((profileName) => ({
+                label: `${profileName === config.activeProfile ? "$(check) " : ""}${profileName}`,
+                description: describeProfile(profiles[profileName]),
+                profile: profileName,
+            })),
+        ];
+
+        const picked = await vscode.window.showQuickPick(items, {
+            title: "Switch Profile",
+            placeHolder: `Active: ${config.activeProfile ?? "(no profile)"}`,
+        });
+        if (!picked) {
+            return;
+        }
+        selected = picked.profile;
+    }
+
+    // treat an empty name as "no profile"
+    const profile = selected || undefined;
+    if (profile === config.activeProfile) {
+        return;
+    }
+
+    try {
+        await setActiveProfile(profile);
+    } catch {
+        // setActiveProfile already reported the error
+        return;
+    }
+
+    vscode.window.showInformationMessage(
+        profile
+            ? `Reactive Themes: Switched to profile "${profile}"`
+            : "Reactive Themes: Using top-level rules (no profile)"
+    );
+}
@@ -3,11 +3,13 @@
 
 import * as vscode from "vscode";
 import { ThemeRule } from "../types";
-import { loadConfig } from "../config";
+import { getActiveRuleSet, loadConfig } from "../config";
 import { extractFileContext, getRuleMatchDetails, FileContext } from "../ruleEngine";
-import { findOverlappingRules } from "../ruleOverlap";
+import { findOverlappingRules, getScheduleKey, isWellFormedRule } from "../ruleOverlap";
 import { formatRuleConditions } from "../utils/ruleFormatters";
 import { ContextFlags } from "../contextManager";
+import { cronMatches, validateCronExpression } from "../utils/cron";
+import { getTimeOfDay, validateLocation } from "../utils/solar";
 import { trackOutputChannel } from "./uiHelpers";
 
 let testRuleOutputChannel: vscode.OutputChannel | undefined;
@@ -28,13 +30,13 @@ export function disposeTestRuleOutputChannel(): void {
     }
 }
 
-interface TestContext extends FileContext, ContextFlags {
+export interface TestContext extends FileContext, ContextFlags {
     languageId: string;
     filePath: string;
     timerFired?: boolean;
 }
 
-interface RuleTestResult {
+export interface RuleTestResult {
     rule: ThemeRule;
     index: number;
     matched: boolean;
@@ -43,24 +45,90 @@ interface RuleTestResult {
 
 type ContextChoice<T> = vscode.QuickPickItem & { value: T };
 
-function ruleHasContextCondition(rule: ThemeRule): boolean {
+// * whether the Test Rule flow should offer context prompts for this rule
+export function ruleHasContextCondition(rule: ThemeRule): boolean {
+    if (!isWellFormedRule(rule)) {
+        return false;
+    }
+
     const when = rule.when;
     return Boolean(
         when.debugSession !== undefined ||
             when.debugType !== undefined ||
             when.testState !== undefined ||
             when.viewMode !== undefined ||
+            when.timeOfDay !== undefined ||
+            when.schedule !== undefined ||
+            when.custom !== undefined ||
             when.timerInterval !== undefined
     );
 }
 
+// current time of day for the configured location, if any
+function detectTimeOfDay(): TestContext["timeOfDay"] {
+    const location = loadConfig().location;
+    if (!location || validateLocation(location)) {
+        return undefined;
+    }
+    return getTimeOfDay(new Date(), location);
+}
+
+// * schedules used by the given rules that match a local time
+export function findActiveSchedules(rules: ThemeRule[], date: Date): string[] {
+    const schedules = new Set<string>();
+    rules.filter(isWellFormedRule).forEach((rule) => {
+        const schedule = getScheduleKey(rule.when);
+        if (schedule && !validateCronExpression(schedule) && cronMatches(schedule, date)) {
+            schedules.add(schedule);
+        }
+    });
+    return Array.from(schedules.values());
+}
+
+// configured schedules (from the active rule set) matching the given local time
+function detectActiveSchedules(date: Date = new Date()): string[] {
+    return findActiveSchedules(getActiveRuleSet(loadConfig()).rules, date);
+}
+
+// * custom provider ids referenced by the given rules
+export function collectCustomProviderIds(rules: ThemeRule[]): string[] {
+    const ids = new Set<string>();
+    rules
+        .filter(isWellFormedRule)
+        .forEach((rule) => Object.keys(rule.when.custom ?? {}).forEach((id) => ids.add(id)));
+    return Array.from(ids.values());
+}
+
+// parse "HH:MM" (today) or "YYYY-MM-DD HH:MM" as a local time
+function parseSimulatedTime(value: string): Date | undefined {
+    const match = /^(?:(\d{4})-(\d{2})-(\d{2})\s+)?(\d{1,2}):(\d{2})$/.exec(value.trim());
+    if (!match) {
+        return undefined;
+    }
+
+    const [, year, month, day, hours, minutes] = match;
+    const date = new Date();
+    if (year) {
+        date.setFullYear(Number(year), Number(month) - 1, Number(day));
+    }
+    date.setHours(Number(hours), Number(minutes), 0, 0);
+
+    const valid =
+        Number(hours) < 24 &&
+        Number(minutes) < 60 &&
+        (!year || (date.getMonth() === Number(month) - 1 && date.getDate() === Number(day)));
+    return valid ? date : undefined;
+}
+
 // * test rules against current file or custom inputs
-export async function testRule(): Promise<void> {
+export async function testRule(customValues: Record<string, string> = {}): Promise<void> {
     console.log("[Reactive Themes] Testing rules");
 
-    const config = loadConfig();
+    // ? test what actually runs: the active profile's rules when it defines its own
+    const { rules } = getActiveRuleSet(loadConfig());
 
-    if (config.rules.length === 0) {
+    if (rules.length === 0) {
+        // new rules are saved to the same rule set, so offer to create one
         const createNew = await vscode.window.showInformationMessage(
             "No rules configured yet.",
             "Create First Rule"
@@ -95,13 +163,14 @@ export async function testRule(): Promise<void> {
     }
 
     // step 3: allow setting context-based conditions when rules use them
-    testContext = await maybeApplyContextFilters(testContext, config.rules, mode);
+    testContext = { ...testContext, custom: { ...customValues } };
+    testContext = await maybeApplyContextFilters(testContext, rules, mode);
     if (!testContext) {
         return;
     }
 
     // step 4: evaluate all rules against test context
-    const results = evaluateAllRules(config.rules, testContext);
+    const results = evaluateAllRules(rules, testContext);
 
     // step 5: display results
     await displayTestResults(testContext, results);
@@ -152,13 +221,13 @@ async function maybeApplyContextFilters(
             },
             {
                 label: "Customize context conditions",
-                description: "Set debug session, test state, view mode, or timer tick",
+                description: "Set debug, test, view, time of day, schedule, custom, or timer context",
                 value: "custom",
             },
         ],
         {
             title: "Context-based rules detected. Provide context for testing?",
-            placeHolder: "Rules use debug/test/view/timer conditions",
+            placeHolder: "Rules use debug/test/view/time-of-day/schedule/custom/timer conditions",
         }
     );
 
@@ -170,7 +239,7 @@ async function maybeApplyContextFilters(
         return context;
     }
 
-    return await promptForContextValues(context);
+    return await promptForContextValues(context, rules);
 }
 
 // get context from current active editor
@@ -193,6 +262,8 @@ function getCurrentFileContext(): TestContext | undefined {
         debugType: vscode.debug.activeDebugSession?.type,
         testState: "none",
         viewMode: "normal",
+        timeOfDay: detectTimeOfDay(),
+        activeSchedules: detectActiveSchedules(),
         timerFired: false,
     };
 }
@@ -245,6 +316,8 @@ async function getCustomTestContext(): Promise<TestContext | undefined> {
         debugSession: "inactive",
         testState: "none",
         viewMode: "normal",
+        timeOfDay: detectTimeOfDay(),
+        activeSchedules: detectActiveSchedules(),
         timerFired: false,
     };
     const derivedFileContext = deriveFileContext(defaultContext);
@@ -273,7 +346,10 @@ function deriveFileContext(
     };
 }
 
-async function promptForContextValues(base: TestContext): Promise<TestContext | undefined> {
+async function promptForContextValues(
+    base: TestContext,
+    rules: ThemeRule[]
+): Promise<TestContext | undefined> {
     const debugSessionChoice = await vscode.window.showQuickPick<
         ContextChoice<"keep" | "any" | "active" | "inactive">
     >(
@@ -370,6 +446,83 @@ async function promptForContextValues(base: TestContext): Promise<TestContext |
         viewMode = viewModeChoice.value;
     }
 
+    const timeOfDayChoice = await vscode.window.showQuickPick<
+        ContextChoice<"keep" | "any" | "day" | "night">
+    >(
+        [
+            { label: `Keep current (${base.timeOfDay ?? "any"})`, value: "keep" },
+            { label: "Any (ignore time of day)", value: "any" },
+            { label: "Day (between sunrise & sunset)", value: "day" },
+            { label: "Night (between sunset & sunrise)", value: "night" },
+        ],
+        {
+            title: "Time of day",
+            placeHolder: "Select time of day for matching",
+        }
+    );
+
+    if (!timeOfDayChoice) {
+        return undefined;
+    }
+
+    let timeOfDay = base.timeOfDay;
+    if (timeOfDayChoice.value === "any") {
+        timeOfDay = undefined;
+    } else if (timeOfDayChoice.value !== "keep") {
+        timeOfDay = timeOfDayChoice.value;
+    }
+
+    const scheduleChoice = await vscode.window.showQuickPick<ContextChoice<"keep" | "simulate">>(
+        [
+            {
+                label: `Keep current (${base.activeSchedules?.length ? base.activeSchedules.join(", ") : "no active schedules"})`,
+                value: "keep",
+            },
+            { label: "Simulate a specific time...", value: "simulate" },
+        ],
+        {
+            title: "Schedule condition",
+            placeHolder: "Schedule rules match when their cron expression matches the time",
+        }
+    );
+
+    if (!scheduleChoice) {
+        return undefined;
+    }
+
+    let activeSchedules = base.activeSchedules;
+    if (scheduleChoice.value === "simulate") {
+        const simulatedTime = await vscode.window.showInputBox({
+            prompt: "Enter a local time to test schedules against",
+            placeHolder: "e.g., 09:30 or 2025-01-06 22:15",
+            validateInput: (value) =>
+                parseSimulatedTime(value) ? undefined : "Use HH:MM or YYYY-MM-DD HH:MM",
+        });
+
+        if (!simulatedTime) {
+            return undefined;
+        }
+        activeSchedules = detectActiveSchedules(parseSimulatedTime(simulatedTime));
+    }
+
+    // custom provider values, one prompt per provider id referenced by rules
+    const custom = { ...(base.custom ?? {}) };
+    for (const id of collectCustomProviderIds(rules)) {
+        const value = await vscode.window.showInputBox({
+            title: `Custom context "${id}"`,
+            prompt: `Enter value reported by the "${id}" provider (leave blank for no value)`,
+            value: custom[id],
+        });
+        if (value === undefined) {
+            return undefined;
+        }
+        if (value.trim()) {
+            custom[id] = value.trim();
+        } else {
+            delete custom[id];
+        }
+    }
+
     const timerFiredChoice = await vscode.window.showQuickPick<ContextChoice<"keep" | boolean>>(
         [
             {
@@ -400,12 +553,16 @@ async function promptForContextValues(base: TestContext): Promise<TestContext |
         debugType,
         testState,
         viewMode,
+        timeOfDay,
+        activeSchedules,
+        custom,
         timerFired,
     };
 }
 
-// evaluate all rules against a test context
-function evaluateAllRules(rules: ThemeRule[], context: TestContext): RuleTestResult[] {
+// * evaluate all rules against a test context
+// ? malformed entries can never match & are reported by config validation, so they're left out
+export function evaluateAllRules(rules: ThemeRule[], context: TestContext): RuleTestResult[] {
     const results: RuleTestResult[] = [];
 
     const fileContext = deriveFileContext(context);
@@ -415,11 +572,18 @@ function evaluateAllRules(rules: ThemeRule[], context: TestContext): RuleTestRes
         debugType: context.debugType,
         testState: context.testState,
         viewMode: context.viewMode,
+        timeOfDay: context.timeOfDay,
+        activeSchedules: context.activeSchedules,
+        custom: context.custom,
         timerTick: 0,
     };
 
     for (let i = 0; i < rules.length; i++) {
         const rule = rules[i];
+        if (!isWellFormedRule(rule)) {
+            continue;
+        }
+
         const ruleOptions = {
             allowTimerRules: context.timerFired,
             activeTimerRuleIndices: context.timerFired ? new Set([i]) : undefined,
@@ -456,6 +620,16 @@ async function displayTestResults(context: TestContext, results: RuleTestResult[
     );
     summaryLines.push(`• Test: \`${context.testState ?? "any"}\``);
     summaryLines.push(`• View: \`${context.viewMode ?? "any"}\``);
+    summaryLines.push(`• Time of day: \`${context.timeOfDay ?? "any"}\``);
+    summaryLines.push(
+        `• Active schedules: \`${context.activeSchedules?.length ? context.activeSchedules.join(", ") : "none"}\``
+    );
+    const customEntries = Object.entries(context.custom ?? {});
+    if (customEntries.length > 0) {
+        summaryLines.push(
+            `• Custom: \`${customEntries.map(([id, value]) => `${id}=${value}`).join(", ")}\``
+        );
+    }
     summaryLines.push(`• Timer tick: \`${context.timerFired ? "fired" : "not fired"}\``);
     summaryLines.push("");
 
@@ -0,0 +1,75 @@
+// src/commands/themeHistory.ts
+// Commands for reviewing recent theme changes & reverting the last one
+
+import * as vscode from "vscode";
+import { ThemeApplyEvent, ThemeManager } from "../themeManager";
+import { getSharedOutputChannel } from "./uiHelpers";
+
+type HistoryQuickPickItem = vscode.QuickPickItem & { event?: ThemeApplyEvent };
+
+// format timestamp as local time w/ date when not today
+function formatTimestamp(timestamp: number): string {
+    const date = new Date(timestamp);
+    const time = date.toLocaleTimeString();
+    return date.toDateString() === new Date().toDateString()
+        ? time
+        : `${date.toLocaleDateString()} ${time}`;
+}
+
+// * show recent theme changes newest first; selecting one logs full details
+export async function showThemeHistory(themeManager: ThemeManager | undefined): Promise<void> {
+    const history = themeManager?.getHistory() ?? [];
+    if (history.length === 0) {
+        vscode.window.showInformationMessage("Reactive Themes: No theme changes recorded yet.");
+        return;
+    }
+
+    const items: HistoryQuickPickItem[] = history.map((event) => ({
+        label: `${event.succeeded ? "$(check)" : "$(error)"} ${event.theme}`,
+        description: formatTimestamp(event.timestamp),
+        detail: event.succeeded
+            ? event.reason ?? "no reason recorded"
+            : `Failed: ${event.error ?? "unknown error"}`,
+        event,
+    }));
+
+    const selected = await vscode.window.showQuickPick(items, {
+        title: `Theme History (last ${history.length})`,
+        placeHolder: "Select an entry to see details in the output panel",
+        matchOnDescription: true,
+        matchOnDetail: true,
+    });
+
+    if (!selected?.event) {
+        return;
+    }
+
+    const event = selected.event;
+    const channel = getSharedOutputChannel();
+    channel.clear();
+    channel.appendLine("THEME CHANGE");
+    channel.appendLine("─".repeat(60));
+    channel.appendLine(`Time:      ${new Date(event.timestamp).toLocaleString()}`);
+    channel.appendLine(`Theme:     ${event.theme}`);
+    channel.appendLine(`Previous:  ${event.previousTheme ?? "(none applied by Reactive Themes)"}`);
+    channel.appendLine(`Trigger:   ${event.reason ?? "(none)"}`);
+    channel.appendLine(`Outcome:   ${event.succeeded ? "applied" : `failed - ${event.error}`}`);
+    channel.appendLine(`Duration:  ${event.durationMs}ms`);
+    channel.show(true);
+}
+
+// * revert the last theme change & keep the reverted theme from coming straight back
+export async function revertTheme(themeManager: ThemeManager | undefined): Promise<void> {
+    const revertedFrom =
+        themeManager?.getCurrentAppliedTheme() ?? themeManager?.getManualOverride();
+    const restored = await themeManager?.revertToPreviousTheme();
+
+    if (!restored) {
+        vscode.window.showInformationMessage("Reactive Themes: No theme change to revert.");
+        return;
+    }
+
+    vscode.window.showInformationMessage(
+        `Reactive Themes: Reverted to "${restored}". "${revertedFrom}" won't be re-applied until another theme is chosen.`
+    );
+}
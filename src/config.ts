@@ -2,17 +2,39 @@
 // Configuration management & validation for Reactive Themes
 
 import * as vscode from "vscode";
-import { ReactiveThemesConfig, ThemeRule } from "./types";
+import { PrecedenceSource, ReactiveThemesConfig, ThemeProfile, ThemeRule } from "./types";
 import { validateInstalledTheme } from "./themeCatalog";
 import { validateGlobPattern } from "./utils/validators";
+import { validateLocation } from "./utils/solar";
+import { validateCronExpression } from "./utils/cron";
+import { findExactDuplicates } from "./ruleLinter";
 
 // configuration section name
 const CONFIG_SECTION = "reactiveThemes";
+const TOP_LEVEL_RULES_SETTING = `${CONFIG_SECTION}.rules`;
 export const DEFAULT_DEBOUNCE_MS = 300;
+export const DEFAULT_PRECEDENCE: readonly PrecedenceSource[] = ["environment", "manual", "rules"];
 
 const VALID_DEBUG_SESSION = new Set<ThemeRule["when"]["debugSession"]>(["active", "inactive"]);
 const VALID_TEST_STATE = new Set<ThemeRule["when"]["testState"]>(["running", "failed", "passed", "none"]);
 const VALID_VIEW_MODE = new Set<ThemeRule["when"]["viewMode"]>(["diff", "merge", "normal"]);
+const VALID_TIME_OF_DAY = new Set<ThemeRule["when"]["timeOfDay"]>(["day", "night"]);
+
+const KNOWN_RULE_KEYS = new Set(["name", "when", "theme"]);
+const KNOWN_CONDITION_KEYS = new Set<string>([
+    "language",
+    "pattern",
+    "workspaceName",
+    "debugSession",
+    "debugType",
+    "testState",
+    "timerInterval",
+    "viewMode",
+    "timeOfDay",
+    "schedule",
+    "custom",
+]);
+const STRING_CONDITION_KEYS = ["language", "pattern", "workspaceName", "debugType"] as const;
 
 let currentConfig: ReactiveThemesConfig = readConfigFromWorkspace();
 
@@ -42,6 +64,13 @@ function readConfigFromWorkspace(): ReactiveThemesConfig {
         defaultTheme: config.get<string>("defaultTheme"),
         debounceMs:
             typeof debounceMs === "number" && debounceMs >= 0 ? debounceMs : DEFAULT_DEBOUNCE_MS,
+        location: config.get<ReactiveThemesConfig["location"]>("location"),
+        manualOverride:
+            config.get<string>("manualOverride", "ignore") === "sticky" ? "sticky" : "ignore",
+        precedence: config.get<PrecedenceSource[]>("precedence"),
+        profiles: config.get<Record<string, ThemeProfile>>("profiles", {}),
+        activeProfile: config.get<string>("activeProfile") || undefined,
+        validation: config.get<string>("validation", "lenient") === "strict" ? "strict" : "lenient",
     };
 }
 
@@ -56,10 +85,40 @@ export function refreshConfig(): ReactiveThemesConfig {
     return loadConfig();
 }
 
-function updateCachedRules(rules: ThemeRule[]): void {
-    currentConfig = {
-        ...currentConfig,
-        rules: [...rules],
+// rules & default theme in effect after applying the active profile
+export interface ActiveRuleSet {
+    profile?: string;
+    rules: ThemeRule[];
+    defaultTheme?: string;
+    setting: string; // settings path the rules come from; rule edits write back here
+}
+
+// * look up a profile by name, ignoring inherited keys like "toString"
+function getProfile(config: ReactiveThemesConfig, name: string): ThemeProfile | undefined {
+    const profiles = config.profiles;
+    return profiles && Object.hasOwn(profiles, name) ? profiles[name] : undefined;
+}
+
+// * resolve rules for the active profile, falling back to top-level settings
+export function getActiveRuleSet(config: ReactiveThemesConfig): ActiveRuleSet {
+    const name = config.activeProfile;
+    const profile = name ? getProfile(config, name) : undefined;
+    if (!name || !profile) {
+        return {
+            rules: config.rules,
+            defaultTheme: config.defaultTheme,
+            setting: TOP_LEVEL_RULES_SETTING,
+        };
+    }
+
+    const profileRules = Array.isArray(profile.rules) ? profile.rules : undefined;
+    return {
+        profile: name,
+        rules: profileRules ?? config.rules,
+        defaultTheme: profile.defaultTheme ?? config.defaultTheme,
+        setting: profileRules
+            ? `${CONFIG_SECTION}.profiles.${name}.rules`
+            : TOP_LEVEL_RULES_SETTING,
     };
 }
 
@@ -75,10 +134,83 @@ export async function setCurrentTheme(theme: string, global: boolean = true): Pr
     await config.update("colorTheme", theme, global);
 }
 
+// theme forced by environment variables, bypassing rule evaluation
+export interface EnvironmentOverride {
+    theme: string;
+    source: string; // variable that produced the override, e.g. "REACTIVE_THEME"
+}
+
+// * read REACTIVE_THEME / REACTIVE_THEME_MODE (dark|light|auto) for containers, CI, & debugging
+export function getEnvironmentOverride(
+    env: NodeJS.ProcessEnv = process.env
+): EnvironmentOverride | undefined {
+    const theme = env.REACTIVE_THEME?.trim();
+    if (theme) {
+        return { theme, source: "REACTIVE_THEME" };
+    }
+
+    const mode = env.REACTIVE_THEME_MODE?.trim().toLowerCase();
+    if (!mode || mode === "auto") {
+        return undefined;
+    }
+    if (mode !== "dark" && mode !== "light") {
+        console.warn(
+            `[Reactive Themes] Ignoring REACTIVE_THEME_MODE="${mode}" (expected dark, light, or auto)`
+        );
+        return undefined;
+    }
+
+    // ? mode resolves to the user's preferred VS Code theme for that color scheme
+    const setting = mode === "dark" ? "preferredDarkColorTheme" : "preferredLightColorTheme";
+    const preferred = vscode.workspace.getConfiguration("workbench").get<string>(setting);
+    return preferred ? { theme: preferred, source: `REACTIVE_THEME_MODE=${mode}` } : undefined;
+}
+
+// * rank theme sources: configured order first, then any unlisted sources in default order
+export function getPrecedence(config: ReactiveThemesConfig): PrecedenceSource[] {
+    const configured = Array.isArray(config.precedence) ? config.precedence : [];
+    const ranked = configured.filter(
+        (source, index) =>
+            DEFAULT_PRECEDENCE.includes(source) && configured.indexOf(source) === index
+    );
+    return [...ranked, ...DEFAULT_PRECEDENCE.filter((source) => !ranked.includes(source))];
+}
+
+// * pick the highest-ranked source that currently offers a theme
+// ? undefined means none does; callers fall back to the default theme, then the original
+export function resolvePrecedence(
+    config: ReactiveThemesConfig,
+    available: Partial<Record<PrecedenceSource, boolean>>
+): PrecedenceSource | undefined {
+    return getPrecedence(config).find((source) => available[source]);
+}
+
+// keys a rule or its 'when' object has that the extension doesn't recognize (usually typos)
+export function getUnknownRuleKeys(rule: ThemeRule): string[] {
+    if (!rule || typeof rule !== "object") {
+        return [];
+    }
+
+    const unknown = Object.keys(rule).filter((key) => !KNOWN_RULE_KEYS.has(key));
+    if (rule.when && typeof rule.when === "object" && !Array.isArray(rule.when)) {
+        Object.keys(rule.when).forEach((key) => {
+            if (!KNOWN_CONDITION_KEYS.has(key)) {
+                unknown.push(`when.${key}`);
+            }
+        });
+    }
+    return unknown;
+}
+
 // validate theme rule structure
+// ? rules come straight from settings.json, so never assume the declared shape
 export function getRuleValidationErrors(rule: ThemeRule): string[] {
     const errors: string[] = [];
 
+    if (!rule || typeof rule !== "object" || Array.isArray(rule)) {
+        return ["Rule must be an object with name, when, & theme"];
+    }
+
     if (!rule.name || typeof rule.name !== "string") {
         errors.push("Rule name must be a non-empty string");
     }
@@ -87,11 +219,18 @@ export function getRuleValidationErrors(rule: ThemeRule): string[] {
         errors.push("Rule theme must be a non-empty string");
     }
 
-    if (!rule.when || typeof rule.when !== "object") {
+    if (!rule.when || typeof rule.when !== "object" || Array.isArray(rule.when)) {
         errors.push("Rule 'when' must be an object with at least one condition");
         return errors;
     }
 
+    STRING_CONDITION_KEYS.forEach((key) => {
+        const value = rule.when[key] as unknown;
+        if (value !== undefined && typeof value !== "string") {
+            errors.push(`Condition "${key}" must be a string`);
+        }
+    });
+
     // require at least one condition (file-based or context-based)
     const hasCondition =
         rule.when.language !== undefined ||
@@ -101,7 +240,10 @@ export function getRuleValidationErrors(rule: ThemeRule): string[] {
         rule.when.debugType !== undefined ||
         rule.when.testState !== undefined ||
         rule.when.timerInterval !== undefined ||
-        rule.when.viewMode !== undefined;
+        rule.when.viewMode !== undefined ||
+        rule.when.timeOfDay !== undefined ||
+        rule.when.schedule !== undefined ||
+        rule.when.custom !== undefined;
 
     if (!hasCondition) {
         errors.push("Rule must specify at least one condition in 'when'");
@@ -116,7 +258,7 @@ export function getRuleValidationErrors(rule: ThemeRule): string[] {
         }
     }
 
-    if (rule.when.pattern) {
+    if (rule.when.pattern && typeof rule.when.pattern === "string") {
         const patternError = validateGlobPattern(rule.when.pattern);
         if (patternError) {
             errors.push(`Invalid glob pattern "${rule.when.pattern}": ${patternError}`);
@@ -141,6 +283,37 @@ export function getRuleValidationErrors(rule: ThemeRule): string[] {
         );
     }
 
+    if (rule.when.schedule !== undefined) {
+        const scheduleError =
+            typeof rule.when.schedule === "string"
+                ? validateCronExpression(rule.when.schedule)
+                : "Schedule must be a cron expression string";
+        if (scheduleError) {
+            errors.push(`Invalid schedule "${rule.when.schedule}": ${scheduleError}`);
+        }
+    }
+
+    if (rule.when.custom !== undefined) {
+        const custom = rule.when.custom as unknown;
+        if (!custom || typeof custom !== "object" || Array.isArray(custom)) {
+            errors.push("Custom conditions must be an object of provider id → value");
+        } else if (Object.keys(custom).length === 0) {
+            errors.push("Custom conditions must specify at least one provider id");
+        } else {
+            Object.entries(custom as Record<string, unknown>).forEach(([id, value]) => {
+                if (typeof value !== "string") {
+                    errors.push(`Custom condition "${id}" must be a string value`);
+                }
+            });
+        }
+    }
+
+    if (rule.when.timeOfDay && !VALID_TIME_OF_DAY.has(rule.when.timeOfDay)) {
+        errors.push(
+            `Invalid timeOfDay value "${rule.when.timeOfDay}" (allowed: ${Array.from(VALID_TIME_OF_DAY).join(", ")})`
+        );
+    }
+
     return errors;
 }
 
@@ -149,8 +322,16 @@ export function validateRule(rule: ThemeRule): boolean {
 }
 
 // * validate configuration & collect errors
-export function validateConfig(config: ReactiveThemesConfig): { valid: boolean; errors: string[] } {
+// unknown keys are warnings in lenient mode & errors in strict mode; strict also rejects duplicates
+export function validateConfig(config: ReactiveThemesConfig): {
+    valid: boolean;
+    errors: string[];
+    warnings: string[];
+} {
     const errors: string[] = [];
+    const warnings: string[] = [];
+    const strict = config.validation === "strict";
+    const unknownKeyIssues = strict ? errors : warnings;
 
     if (typeof config.debounceMs !== "number" || config.debounceMs < 0) {
         errors.push("reactiveThemes.debounceMs must be a non-negative number");
@@ -158,33 +339,138 @@ export function validateConfig(config: ReactiveThemesConfig): { valid: boolean;
 
     if (!Array.isArray(config.rules)) {
         errors.push("reactiveThemes.rules must be an array");
-        return { valid: false, errors };
+        return { valid: false, errors, warnings };
+    }
+
+    collectRuleErrors(config.rules, errors, unknownKeyIssues);
+    if (strict) {
+        collectDuplicateErrors(config.rules, errors);
+    }
+
+    if (config.defaultTheme) {
+        const validation = validateInstalledTheme(config.defaultTheme);
+        if (!validation.valid && validation.message) {
+            errors.push(validation.message);
+        }
     }
 
-    config.rules.forEach((rule, index) => {
+    const profiles = config.profiles ?? {};
+    Object.entries(profiles).forEach(([name, profile]) => {
+        if (!profile || typeof profile !== "object") {
+            errors.push(`Profile "${name}" must be an object`);
+            return;
+        }
+        if (profile.rules !== undefined) {
+            if (Array.isArray(profile.rules)) {
+                collectRuleErrors(profile.rules, errors, unknownKeyIssues, `Profile "${name}": `);
+                if (strict) {
+                    collectDuplicateErrors(profile.rules, errors, `Profile "${name}": `);
+                }
+            } else {
+                errors.push(`Profile "${name}": rules must be an array`);
+            }
+        }
+        if (profile.defaultTheme) {
+            const validation = validateInstalledTheme(profile.defaultTheme);
+            if (!validation.valid && validation.message) {
+                errors.push(`Profile "${name}": ${validation.message}`);
+            }
+        }
+    });
+
+    if (config.activeProfile && !getProfile(config, config.activeProfile)) {
+        errors.push(
+            `reactiveThemes.activeProfile "${config.activeProfile}" is not defined in reactiveThemes.profiles`
+        );
+    }
+
+    if (config.precedence !== undefined) {
+        if (!Array.isArray(config.precedence)) {
+            errors.push("reactiveThemes.precedence must be an array");
+        } else {
+            const unknown = config.precedence.filter(
+                (source) => !DEFAULT_PRECEDENCE.includes(source)
+            );
+            if (unknown.length > 0) {
+                errors.push(
+                    `reactiveThemes.precedence has unknown sources: ${unknown.map(String).join(", ")} (expected ${DEFAULT_PRECEDENCE.join(", ")})`
+                );
+            }
+            if (new Set(config.precedence).size !== config.precedence.length) {
+                errors.push("reactiveThemes.precedence lists a source more than once");
+            }
+        }
+    }
+
+    // time-of-day rules need a location to compute sunrise/sunset
+    if (config.location !== undefined) {
+        const locationError = validateLocation(config.location);
+        if (locationError) {
+            errors.push(`reactiveThemes.location is invalid: ${locationError}`);
+        }
+    } else if (getActiveRuleSet(config).rules.some((rule) => rule?.when?.timeOfDay !== undefined)) {
+        errors.push("Rules use timeOfDay but reactiveThemes.location is not set");
+    }
+
+    return {
+        valid: errors.length === 0,
+        errors,
+        warnings,
+    };
+}
+
+function collectRuleErrors(
+    rules: ThemeRule[],
+    errors: string[],
+    unknownKeyIssues: string[],
+    prefix: string = ""
+): void {
+    rules.forEach((rule, index) => {
+        const unknownKeys = getUnknownRuleKeys(rule);
+        if (unknownKeys.length > 0) {
+            unknownKeyIssues.push(
+                `${prefix}Rule at index ${index} has unknown keys: ${unknownKeys.join(", ")}`
+            );
+        }
+
         const ruleErrors = getRuleValidationErrors(rule);
         if (ruleErrors.length > 0) {
-            errors.push(`Rule at index ${index} is invalid: ${ruleErrors.join("; ")}`);
+            errors.push(`${prefix}Rule at index ${index} is invalid: ${ruleErrors.join("; ")}`);
             return;
         }
 
         const themeValidation = validateInstalledTheme(rule.theme);
         if (!themeValidation.valid && themeValidation.message) {
-            errors.push(`Rule "${rule.name}": ${themeValidation.message}`);
+            errors.push(`${prefix}Rule "${rule.name}": ${themeValidation.message}`);
         }
     });
+}
 
-    if (config.defaultTheme) {
-        const validation = validateInstalledTheme(config.defaultTheme);
-        if (!validation.valid && validation.message) {
-            errors.push(validation.message);
-        }
+// exact duplicates never apply (first match wins); lenient mode leaves them to Lint Rules
+function collectDuplicateErrors(rules: ThemeRule[], errors: string[], prefix: string = ""): void {
+    findExactDuplicates(rules).forEach((issue) => {
+        errors.push(`${prefix}Rule at index ${issue.ruleIndex}: ${issue.message}`);
+    });
+}
+
+// * write rules back to the setting they were read from (active profile or top-level)
+async function writeActiveRules(rules: ThemeRule[]): Promise<void> {
+    const ruleSet = getActiveRuleSet(currentConfig);
+    const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
+
+    if (ruleSet.setting === TOP_LEVEL_RULES_SETTING || !ruleSet.profile) {
+        await vscodeConfig.update("rules", rules, vscode.ConfigurationTarget.Global);
+        currentConfig = { ...currentConfig, rules: [...rules] };
+        return;
     }
 
-    return {
-        valid: errors.length === 0,
-        errors,
+    // ? profiles is a single setting, so the whole object is written back
+    const profiles = {
+        ...currentConfig.profiles,
+        [ruleSet.profile]: { ...getProfile(currentConfig, ruleSet.profile), rules: [...rules] },
     };
+    await vscodeConfig.update("profiles", profiles, vscode.ConfigurationTarget.Global);
+    currentConfig = { ...currentConfig, profiles };
 }
 
 function ensureThemeIsInstalled(themeName: string): void {
@@ -194,18 +480,14 @@ function ensureThemeIsInstalled(themeName: string): void {
     }
 }
 
-// * save a new rule to configuration
+// * save a new rule to the active rule set
 export async function saveRule(rule: ThemeRule): Promise<void> {
     return queueConfigUpdate(async () => {
         try {
             ensureThemeIsInstalled(rule.theme);
 
-            const config = loadConfig();
-            const updatedRules = [...config.rules, rule];
-
-            const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
-            await vscodeConfig.update("rules", updatedRules, vscode.ConfigurationTarget.Global);
-            updateCachedRules(updatedRules);
+            const { rules } = getActiveRuleSet(loadConfig());
+            await writeActiveRules([...rules, rule]);
 
             console.log("[Reactive Themes] Rule saved:", rule.name);
         } catch (error) {
@@ -217,24 +499,21 @@ export async function saveRule(rule: ThemeRule): Promise<void> {
     });
 }
 
-// * update an existing rule at a specific index
+// * update an existing rule in the active rule set at a specific index
 export async function updateRule(index: number, rule: ThemeRule): Promise<void> {
     return queueConfigUpdate(async () => {
         try {
             ensureThemeIsInstalled(rule.theme);
 
-            const config = loadConfig();
+            const { rules } = getActiveRuleSet(loadConfig());
 
-            if (index < 0 || index >= config.rules.length) {
+            if (index < 0 || index >= rules.length) {
                 throw new Error(`Invalid rule index: ${index}`);
             }
 
-            const updatedRules = [...config.rules];
+            const updatedRules = [...rules];
             updatedRules[index] = rule;
-
-            const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
-            await vscodeConfig.update("rules", updatedRules, vscode.ConfigurationTarget.Global);
-            updateCachedRules(updatedRules);
+            await writeActiveRules(updatedRules);
 
             console.log("[Reactive Themes] Rule updated at index", index, ":", rule.name);
         } catch (error) {
@@ -246,22 +525,18 @@ export async function updateRule(index: number, rule: ThemeRule): Promise<void>
     });
 }
 
-// * delete a rule at a specific index
+// * delete a rule from the active rule set at a specific index
 export async function deleteRule(index: number): Promise<void> {
     return queueConfigUpdate(async () => {
         try {
-            const config = loadConfig();
+            const { rules } = getActiveRuleSet(loadConfig());
 
-            if (index < 0 || index >= config.rules.length) {
+            if (index < 0 || index >= rules.length) {
                 throw new Error(`Invalid rule index: ${index}`);
             }
 
-            const deletedRule = config.rules[index];
-            const updatedRules = config.rules.filter((_, i) => i !== index);
-
-            const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
-            await vscodeConfig.update("rules", updatedRules, vscode.ConfigurationTarget.Global);
-            updateCachedRules(updatedRules);
+            const deletedRule = rules[index];
+            await writeActiveRules(rules.filter((_, i) => i !== index));
 
             console.log("[Reactive Themes] Rule deleted:", deletedRule.name);
         } catch (error) {
@@ -273,13 +548,11 @@ export async function deleteRule(index: number): Promise<void> {
     });
 }
 
-// * update all rules at once (for bulk operations like reordering)
+// * replace the active rule set at once (for bulk operations like reordering)
 export async function updateAllRules(rules: ThemeRule[]): Promise<void> {
     return queueConfigUpdate(async () => {
         try {
-            const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
-            await vscodeConfig.update("rules", rules, vscode.ConfigurationTarget.Global);
-            updateCachedRules(rules);
+            await writeActiveRules(rules);
 
             console.log("[Reactive Themes] Bulk rule update completed:", rules.length, "rules");
         } catch (error) {
@@ -290,3 +563,25 @@ export async function updateAllRules(rules: ThemeRule[]): Promise<void> {
         }
     });
 }
+
+// * switch the active profile; undefined returns to top-level rules
+export async function setActiveProfile(name: string | undefined): Promise<void> {
+    return queueConfigUpdate(async () => {
+        try {
+            if (name !== undefined && !getProfile(currentConfig, name)) {
+                throw new Error(`Unknown profile "${name}"`);
+            }
+
+            const vscodeConfig = vscode.workspace.getConfiguration(CONFIG_SECTION);
+            await vscodeConfig.update("activeProfile", name, vscode.ConfigurationTarget.Global);
+            currentConfig = { ...currentConfig, activeProfile: name };
+
+            console.log("[Reactive Themes] Active profile:", name ?? "(none)");
+        } catch (error) {
+            const message = `Failed to switch profile: ${error instanceof Error ? error.message : String(error)}`;
+            console.error("[Reactive Themes]", message);
+            vscode.window.showErrorMessage(message);
+            throw error;
+        }
+    });
+}
@@ -1,14 +1,16 @@
 // src/contextManager.ts
-// Track VS Code context state for debug/test/view/timer triggers
+// Track VS Code context state for debug/test/view/timer/time-of-day/schedule/custom triggers
 
 import * as vscode from "vscode";
 import { RuleCondition } from "./types";
 
 export type ContextFlags = Pick<
     RuleCondition,
-    "debugSession" | "debugType" | "testState" | "viewMode"
+    "debugSession" | "debugType" | "testState" | "viewMode" | "timeOfDay"
 > & {
     timerTick?: number; // Internal counter for timer-based rules
+    activeSchedules?: string[]; // Cron expressions matching the current minute
+    custom?: Record<string, string>; // Values from registered custom context providers
 };
 
 // current VS Code context snapshot
@@ -54,6 +56,41 @@ export class ContextManager {
         }
     }
 
+    public setTimeOfDay(timeOfDay: "day" | "night" | undefined): void {
+        if (this.context.timeOfDay !== timeOfDay) {
+            this.context.timeOfDay = timeOfDay;
+            this.onDidChangeContextEmitter.fire(this.getContext());
+        }
+    }
+
+    public setActiveSchedules(schedules: string[]): void {
+        const current = this.context.activeSchedules ?? [];
+        const changed =
+            current.length !== schedules.length ||
+            schedules.some((schedule) => !current.includes(schedule));
+
+        if (changed) {
+            this.context.activeSchedules = [...schedules];
+            this.onDidChangeContextEmitter.fire(this.getContext());
+        }
+    }
+
+    public setCustomValue(id: string, value: string | undefined): void {
+        const current = this.context.custom ?? {};
+        if (current[id] === value) {
+            return;
+        }
+
+        const next = { ...current };
+        if (value === undefined) {
+            delete next[id];
+        } else {
+            next[id] = value;
+        }
+        this.context.custom = next;
+        this.onDidChangeContextEmitter.fire(this.getContext());
+    }
+
     public incrementTimerTick(): void {
         this.context.timerTick = (this.context.timerTick || 0) + 1;
         this.onDidChangeContextEmitter.fire(this.getContext());
@@ -4,21 +4,36 @@
 import * as vscode from "vscode";
 import { ThemeManager } from "./themeManager";
 import { evaluateRules, extractFileContext } from "./ruleEngine";
-import { loadConfig, refreshConfig, validateConfig } from "./config";
+import { validateInstalledTheme } from "./themeCatalog";
+import {
+    EnvironmentOverride,
+    getActiveRuleSet,
+    getCurrentTheme,
+    getEnvironmentOverride,
+    loadConfig,
+    refreshConfig,
+    resolvePrecedence,
+    validateConfig,
+} from "./config";
 import { createRuleFromCurrentFile } from "./commands/createRule";
 import { manageRules } from "./commands/manageRules";
 import { cleanupDuplicateRules } from "./commands/cleanupRules";
 import { disposeTestRuleOutputChannel, testRule } from "./commands/testRule";
 import { lintRulesCommand } from "./commands/lintRules";
-import { registerExplainThemeCommands } from "./commands/explainTheme";
+import { explainThemeDecision, registerExplainThemeCommands } from "./commands/explainTheme";
+import { revertTheme, showThemeHistory } from "./commands/themeHistory";
+import { switchProfile } from "./commands/switchProfile";
 import { ContextManager } from "./contextManager";
 import { DebugTrigger } from "./triggers/debugTrigger";
 import { TimerTrigger } from "./triggers/timerTrigger";
 import { ViewTrigger } from "./triggers/viewTrigger";
 import { TestTrigger } from "./triggers/testTrigger";
-import { ThemeRule } from "./types";
+import { SolarTrigger } from "./triggers/solarTrigger";
+import { ScheduleTrigger } from "./triggers/scheduleTrigger";
+import { ReactiveThemesConfig, ThemeRule } from "./types";
 import { formatRuleConditions } from "./utils/ruleFormatters";
 import { disposeOutputChannels } from "./commands/uiHelpers";
+import { ReactiveThemesApi, createExtensionApi } from "./api";
 
 // global theme manager instance
 let themeManager: ThemeManager | undefined;
@@ -29,30 +44,74 @@ let debugTrigger: DebugTrigger | undefined;
 let timerTrigger: TimerTrigger | undefined;
 let viewTrigger: ViewTrigger | undefined;
 let testTrigger: TestTrigger | undefined;
+let solarTrigger: SolarTrigger | undefined;
+let scheduleTrigger: ScheduleTrigger | undefined;
+
+// theme forced via REACTIVE_THEME / REACTIVE_THEME_MODE for this process
+let environmentOverride: EnvironmentOverride | undefined;
+
+// set when strict validation rejects the config; rules stop applying until it's fixed
+let configRejected = false;
 
 // * activate extension & register commands, listeners, & theme manager
-export async function activate(context: vscode.ExtensionContext) {
+export async function activate(context: vscode.ExtensionContext): Promise<ReactiveThemesApi> {
     console.log("[Reactive Themes] Extension activating...");
 
     // load initial configuration & set enabled state
     const config = refreshConfig();
+    environmentOverride = getEnvironmentOverride();
+    if (environmentOverride) {
+        // check once at startup instead of failing on every editor change
+        const themeValidation = validateInstalledTheme(environmentOverride.theme);
+        if (!themeValidation.valid) {
+            console.warn(
+                `[Reactive Themes] Ignoring ${environmentOverride.source}:`,
+                themeValidation.message
+            );
+            vscode.window.showWarningMessage(
+                `Reactive Themes: Ignoring ${environmentOverride.source}. ${themeValidation.message}`
+            );
+            environmentOverride = undefined;
+        } else {
+            console.log(
+                `[Reactive Themes] ${environmentOverride.source} forces theme "${environmentOverride.theme}"`
+            );
+        }
+    }
 
     // initialize theme manager
-    themeManager = new ThemeManager(config.debounceMs);
+    // globalState lets the original theme survive crashes & reloads
+    themeManager = new ThemeManager({
+        debounceMs: config.debounceMs,
+        state: context.globalState,
+    });
     await themeManager.setEnabled(config.enabled);
 
     // initialize context manager
     contextManager = new ContextManager();
     let lastContextSnapshot = contextManager.getContext();
+    const extensionApi = createExtensionApi(contextManager, themeManager, () =>
+        explainThemeDecision({
+            themeManager,
+            contextManager,
+            timerTrigger,
+            solarTrigger,
+            environmentOverride,
+        })
+    );
 
     // initialize triggers
     debugTrigger = new DebugTrigger(contextManager);
     viewTrigger = new ViewTrigger(contextManager);
     testTrigger = new TestTrigger(contextManager);
+    solarTrigger = new SolarTrigger(contextManager);
+    solarTrigger.setLocation(config.location);
+    scheduleTrigger = new ScheduleTrigger(contextManager);
+    scheduleTrigger.registerScheduleRules(getActiveRuleSet(config).rules);
 
     // initialize timer trigger with callback to apply theme
     timerTrigger = new TimerTrigger(contextManager, (ruleIndices: number[], rules: ThemeRule[]) => {
-        if (!themeManager || !themeManager.getEnabled()) {
+        if (!themeManager || !themeManager.getEnabled() || configRejected) {
             return;
         }
 
@@ -73,15 +132,23 @@ export async function activate(context: vscode.ExtensionContext) {
             timerOnly: true,
         });
 
-        if (result.matched && result.rule) {
+        // a higher-ranked environment override or manual pick keeps the timer rule from applying
+        const winner = resolvePrecedence(loadConfig(), {
+            environment: environmentOverride !== undefined,
+            manual: themeManager.getManualOverride() !== undefined,
+            rules: result.matched,
+        });
+        if (winner === "rules" && result.rule) {
             const ruleDescription =
                 formatRuleConditions(result.rule, { mode: "compact" }) || "matched timer rule";
-            themeManager.applyTheme(result.rule.theme, `timer trigger: ${ruleDescription}`);
+            themeManager.applyTheme(result.rule.theme, `timer trigger: ${ruleDescription}`, {
+                overrideManual: true,
+            });
         }
     });
 
     // register timer-based rules
-    timerTrigger.registerTimerRules(config.rules);
+    timerTrigger.registerTimerRules(getActiveRuleSet(config).rules);
 
     // listen to context changes and re-evaluate rules
     contextManager.onDidChangeContext((newContext) => {
@@ -92,7 +159,12 @@ export async function activate(context: vscode.ExtensionContext) {
             newContext.debugSession === lastContextSnapshot.debugSession &&
             newContext.debugType === lastContextSnapshot.debugType &&
             newContext.testState === lastContextSnapshot.testState &&
-            newContext.viewMode === lastContextSnapshot.viewMode;
+            newContext.viewMode === lastContextSnapshot.viewMode &&
+            newContext.timeOfDay === lastContextSnapshot.timeOfDay &&
+            (newContext.activeSchedules ?? []).join("\n") ===
+                (lastContextSnapshot.activeSchedules ?? []).join("\n") &&
+            JSON.stringify(newContext.custom ?? {}) ===
+                JSON.stringify(lastContextSnapshot.custom ?? {});
 
         lastContextSnapshot = newContext;
 
@@ -106,7 +178,7 @@ export async function activate(context: vscode.ExtensionContext) {
     });
 
     // validate configuration & warn on errors
-    const validation = validateConfig(config);
+    const validation = checkConfig(config);
     if (!validation.valid) {
         console.warn("[Reactive Themes] Configuration errors:", validation.errors);
         vscode.window.showWarningMessage(
@@ -115,9 +187,8 @@ export async function activate(context: vscode.ExtensionContext) {
     }
 
     // apply theme for currently active editor on activation
-    if (vscode.window.activeTextEditor) {
-        handleEditorChange(vscode.window.activeTextEditor);
-    }
+    // ? runs w/o an editor too, so environment overrides apply on an empty window
+    handleEditorChange(vscode.window.activeTextEditor);
 
     // command: toggle enable/disable
     const toggleCommand = vscode.commands.registerCommand("reactiveThemes.toggle", async () => {
@@ -128,6 +199,10 @@ export async function activate(context: vscode.ExtensionContext) {
         const currentState = themeManager.getEnabled();
         const newState = !currentState;
         await themeManager.setEnabled(newState);
+        if (newState) {
+            // re-enabling is an explicit request for automatic switching
+            themeManager.resumeAutomaticSwitching();
+        }
 
         // persist enabled state to configuration
         vscode.workspace.getConfiguration("reactiveThemes").update("enabled", newState, true);
@@ -140,7 +215,7 @@ export async function activate(context: vscode.ExtensionContext) {
     // command: reload rules & re-evaluate current editor
     const reloadCommand = vscode.commands.registerCommand("reactiveThemes.reloadRules", () => {
         const config = refreshConfig();
-        const validation = validateConfig(config);
+        const validation = checkConfig(config);
 
         if (!validation.valid) {
             vscode.window.showErrorMessage(
@@ -150,12 +225,20 @@ export async function activate(context: vscode.ExtensionContext) {
         }
 
         // re-register timer rules
+        const ruleSet = getActiveRuleSet(config);
         if (timerTrigger) {
-            timerTrigger.registerTimerRules(config.rules);
+            timerTrigger.registerTimerRules(ruleSet.rules);
+        }
+        if (solarTrigger) {
+            solarTrigger.setLocation(config.location);
+        }
+        if (scheduleTrigger) {
+            scheduleTrigger.registerScheduleRules(ruleSet.rules);
         }
 
         vscode.window.showInformationMessage(
-            `Reactive Themes: Reloaded ${config.rules.length} rule(s)`
+            `Reactive Themes: Reloaded ${ruleSet.rules.length} rule(s)` +
+                (ruleSet.profile ? ` from profile "${ruleSet.profile}"` : "")
         );
 
         // re-evaluate w/ current editor
@@ -174,9 +257,10 @@ export async function activate(context: vscode.ExtensionContext) {
             }
 
             const config = loadConfig();
+            const ruleSet = getActiveRuleSet(config);
             const editor = vscode.window.activeTextEditor;
             const currentContext = contextManager?.getContext() || {};
-            const result = evaluateRules(config.rules, editor, currentContext);
+            const result = evaluateRules(ruleSet.rules, editor, currentContext);
 
             const fileContext = extractFileContext(editor);
 
@@ -188,9 +272,26 @@ export async function activate(context: vscode.ExtensionContext) {
             message += `**Current Context:**\n`;
             message += `- Debug: \`${currentContext.debugSession}\`${currentContext.debugType ? ` (${currentContext.debugType})` : ""}\n`;
             message += `- Test: \`${currentContext.testState}\`\n`;
-            message += `- View: \`${currentContext.viewMode}\`\n\n`;
-
-            if (result.matched && result.rule) {
+            message += `- View: \`${currentContext.viewMode}\`\n`;
+            message += `- Time of day: \`${currentContext.timeOfDay ?? "N/A"}\`\n`;
+            message += `- Active schedules: \`${currentContext.activeSchedules?.join(", ") || "none"}\`\n`;
+            const customEntries = Object.entries(currentContext.custom ?? {});
+            message += `- Custom: \`${customEntries.map(([id, value]) => `${id}=${value}`).join(", ") || "none"}\`\n`;
+            message += `- Profile: \`${ruleSet.profile ?? "none"}\`\n\n`;
+
+            const manualOverride = themeManager?.getManualOverride();
+            const winner = resolvePrecedence(config, {
+                environment: environmentOverride !== undefined,
+                manual: manualOverride !== undefined,
+                rules: result.matched,
+            });
+            if (winner === "environment" && environmentOverride) {
+                message += `**Environment Override:** ${environmentOverride.source}\n`;
+                message += `- Theme: \`${environmentOverride.theme}\` (rules are not applied)\n`;
+            } else if (winner === "manual" && manualOverride) {
+                message += `**Manual Override:** \`${manualOverride}\`\n`;
+                message += `- Automatic switching paused (run Resume Automatic Theme Switching)\n`;
+            } else if (result.matched && result.rule) {
                 // use centralized formatter for consistent condition display
                 const ruleConditions = formatRuleConditions(result.rule, {
                     mode: "compact",
@@ -200,8 +301,8 @@ export async function activate(context: vscode.ExtensionContext) {
                 message += `- Theme: \`${result.theme}\`\n`;
             } else {
                 message += `**No rules matched**\n`;
-                if (config.defaultTheme) {
-                    message += `- Using default theme: \`${config.defaultTheme}\`\n`;
+                if (ruleSet.defaultTheme) {
+                    message += `- Using default theme: \`${ruleSet.defaultTheme}\`\n`;
                 } else {
                     message += `- Using original theme\n`;
                 }
@@ -238,7 +339,7 @@ export async function activate(context: vscode.ExtensionContext) {
 
     // command: test rule
     const testRuleCommand = vscode.commands.registerCommand("reactiveThemes.testRule", async () => {
-        await testRule();
+        await testRule(contextManager?.getContext().custom);
     });
 
     // command: lint rules
@@ -249,10 +350,58 @@ export async function activate(context: vscode.ExtensionContext) {
         }
     );
 
+    // command: show theme change history
+    const showThemeHistoryCommand = vscode.commands.registerCommand(
+        "reactiveThemes.showThemeHistory",
+        async () => {
+            await showThemeHistory(themeManager);
+        }
+    );
+
+    // command: resume switching after a manual theme change
+    const resumeCommand = vscode.commands.registerCommand(
+        "reactiveThemes.resumeAutomaticSwitching",
+        () => {
+            if (!themeManager) {
+                return;
+            }
+
+            if (!themeManager.resumeAutomaticSwitching()) {
+                vscode.window.showInformationMessage(
+                    "Reactive Themes: Automatic switching is not paused."
+                );
+                return;
+            }
+
+            vscode.window.showInformationMessage("Reactive Themes: Automatic switching resumed");
+            if (vscode.window.activeTextEditor) {
+                handleEditorChange(vscode.window.activeTextEditor);
+            }
+        }
+    );
+
+    // command: switch profile (optional name arg for keybindings & other extensions)
+    const switchProfileCommand = vscode.commands.registerCommand(
+        "reactiveThemes.switchProfile",
+        async (name?: string) => {
+            await switchProfile(typeof name === "string" ? name : undefined);
+        }
+    );
+
+    // command: revert last theme change
+    const revertThemeCommand = vscode.commands.registerCommand(
+        "reactiveThemes.revertTheme",
+        async () => {
+            await revertTheme(themeManager);
+        }
+    );
+
     registerExplainThemeCommands(context, {
         themeManager,
         contextManager,
         timerTrigger,
+        solarTrigger,
+        environmentOverride,
     });
 
     // listen for active editor changes & apply theme rules
@@ -270,16 +419,57 @@ export async function activate(context: vscode.ExtensionContext) {
 
     // listen for reactiveThemes.* config changes & reload
     const configChangeListener = vscode.workspace.onDidChangeConfiguration(async (event) => {
+        // theme picked outside the extension (Color Theme picker, settings.json, OS auto-detect)
+        if (event.affectsConfiguration("workbench.colorTheme") && themeManager) {
+            const config = loadConfig();
+            // ? a manual pick only counts when an active environment override doesn't outrank it
+            const manualOutranksEnvironment =
+                !environmentOverride ||
+                resolvePrecedence(config, { environment: true, manual: true }) === "manual";
+            if (
+                config.manualOverride !== "ignore" &&
+                themeManager.getEnabled() &&
+                manualOutranksEnvironment &&
+                themeManager.noteExternalThemeChange(getCurrentTheme())
+            ) {
+                const choice = await vscode.window.showInformationMessage(
+                    "Reactive Themes: Manual theme change detected; automatic switching paused.",
+                    "Resume"
+                );
+                if (choice === "Resume") {
+                    await vscode.commands.executeCommand("reactiveThemes.resumeAutomaticSwitching");
+                }
+            }
+        }
+
         if (event.affectsConfiguration("reactiveThemes")) {
             const config = refreshConfig();
+            const wasRejected = configRejected;
+            const validation = checkConfig(config);
+            if (configRejected && !wasRejected) {
+                console.warn("[Reactive Themes] Configuration errors:", validation.errors);
+                vscode.window.showWarningMessage(
+                    "Reactive Themes: Strict validation rejected the configuration; rules are paused until it's fixed. Check the developer console for details."
+                );
+            }
             if (themeManager) {
                 themeManager.setDebounceMs(config.debounceMs);
                 await themeManager.setEnabled(config.enabled);
+                if (config.manualOverride === "ignore") {
+                    themeManager.resumeAutomaticSwitching();
+                }
             }
 
-            // re-register timer rules when config changes
+            // re-register timer rules when config changes (incl. profile switches)
+            const ruleSet = getActiveRuleSet(config);
             if (timerTrigger) {
-                timerTrigger.registerTimerRules(config.rules);
+                timerTrigger.registerTimerRules(ruleSet.rules);
+            }
+            if (solarTrigger) {
+                solarTrigger.setLocation(config.location);
+            }
+            if (scheduleTrigger) {
+                scheduleTrigger.registerScheduleRules(ruleSet.rules);
             }
 
             // re-evaluate rules w/ current editor
@@ -299,6 +489,10 @@ export async function activate(context: vscode.ExtensionContext) {
         cleanupRulesCommand,
         testRuleCommand,
         lintRulesCommandRegistration,
+        showThemeHistoryCommand,
+        revertThemeCommand,
+        resumeCommand,
+        switchProfileCommand,
         editorChangeListener,
         languageChangeListener,
         configChangeListener,
@@ -321,13 +515,32 @@ export async function activate(context: vscode.ExtensionContext) {
     if (testTrigger) {
         context.subscriptions.push(testTrigger);
     }
+    if (solarTrigger) {
+        context.subscriptions.push(solarTrigger);
+    }
+    if (scheduleTrigger) {
+        context.subscriptions.push(scheduleTrigger);
+    }
 
     console.log("[Reactive Themes] Extension activated successfully");
+
+    // expose custom context provider registration to other extensions
+    return extensionApi;
+}
+
+// * validate config & record whether strict mode rejects it
+function checkConfig(config: ReactiveThemesConfig): ReturnType<typeof validateConfig> {
+    const validation = validateConfig(config);
+    configRejected = config.validation === "strict" && !validation.valid;
+    if (validation.warnings.length > 0) {
+        console.warn("[Reactive Themes] Configuration warnings:", validation.warnings);
+    }
+    return validation;
 }
 
 // * handle editor change events & apply appropriate theme
 function handleEditorChange(editor: vscode.TextEditor | undefined): void {
-    if (!themeManager || !editor) {
+    if (!themeManager) {
         return;
     }
 
@@ -339,21 +552,44 @@ function handleEditorChange(editor: vscode.TextEditor | undefined): void {
         return;
     }
 
-    // get current context
-    const currentContext = contextManager?.getContext() || {};
-
-    // evaluate rules against current editor and context
-    const result = evaluateRules(config.rules, editor, currentContext);
+    // evaluate rules for the active profile against current editor and context
+    // ? rules need an editor, and strict validation rejection pauses them
+    const ruleSet = getActiveRuleSet(config);
+    const result =
+        editor && !configRejected
+            ? evaluateRules(ruleSet.rules, editor, contextManager?.getContext() || {})
+            : undefined;
+
+    // highest-ranked source w/ a theme wins (reactiveThemes.precedence)
+    const manualTheme = themeManager.getManualOverride();
+    const winner = resolvePrecedence(config, {
+        environment: environmentOverride !== undefined,
+        manual: manualTheme !== undefined,
+        rules: result?.matched === true && result.theme !== undefined,
+    });
 
-    if (result.matched && result.theme) {
+    if (winner === "environment" && environmentOverride) {
+        themeManager.applyTheme(
+            environmentOverride.theme,
+            `environment override: ${environmentOverride.source}`,
+            { overrideManual: true }
+        );
+    } else if (winner === "manual" && manualTheme) {
+        // ? a higher-ranked source may have replaced the manual pick; restore it once that ends
+        if (getCurrentTheme() !== manualTheme) {
+            themeManager.applyTheme(manualTheme, "manual override", { overrideManual: true });
+        }
+    } else if (winner === "rules" && result?.theme) {
         // rule matched - apply its theme
         const ruleDescription = result.rule
             ? formatRuleConditions(result.rule, { mode: "compact" })
             : "unknown";
-        themeManager.applyTheme(result.theme, `matched rule: ${ruleDescription}`);
-    } else {
+        themeManager.applyTheme(result.theme, `matched rule: ${ruleDescription}`, {
+            overrideManual: true,
+        });
+    } else if (result) {
         // no rules matched - apply fallback theme
-        themeManager.applyFallback(config.defaultTheme);
+        themeManager.applyFallback(ruleSet.defaultTheme);
     }
 }
 
@@ -58,6 +58,11 @@ export function getRuleMatchDetails(
     options: MatchOptions = {},
     ruleIndex: number = -1
 ): RuleMatchDetails {
+    // malformed settings entries never match instead of throwing on every editor change
+    if (!rule || !rule.when || typeof rule.when !== "object") {
+        return { matched: false, reasons: ["✗ Malformed rule: 'when' must be an object"] };
+    }
+
     const when = rule.when;
     const reasons: string[] = [];
     let matched = true;
@@ -156,6 +161,41 @@ export function getRuleMatchDetails(
         );
     }
 
+    if (when.timeOfDay !== undefined) {
+        const matches = context.timeOfDay === when.timeOfDay;
+        const contextPhase = context.timeOfDay || "(location not set)";
+        matched = matched && matches;
+        reasons.push(
+            matches
+                ? `✓ Time of day matches: "${contextPhase}" === "${when.timeOfDay}"`
+                : `✗ Time of day mismatch: "${contextPhase}" !== "${when.timeOfDay}"`
+        );
+    }
+
+    if (when.schedule !== undefined) {
+        const schedule = String(when.schedule).trim();
+        const matches = (context.activeSchedules ?? []).includes(schedule);
+        matched = matched && matches;
+        reasons.push(
+            matches
+                ? `✓ Schedule active: "${schedule}" matches the current time`
+                : `✗ Schedule inactive: "${schedule}" doesn't match the current time`
+        );
+    }
+
+    if (when.custom !== undefined) {
+        Object.entries(when.custom ?? {}).forEach(([id, expected]) => {
+            const actual = context.custom?.[id];
+            const matches = actual === expected;
+            matched = matched && matches;
+            reasons.push(
+                matches
+                    ? `✓ Custom "${id}" matches: "${actual}" === "${expected}"`
+                    : `✗ Custom "${id}" mismatch: "${actual ?? "(no provider value)"}" !== "${expected}"`
+            );
+        });
+    }
+
     if (when.timerInterval !== undefined) {
         const timerAllowed = options.allowTimerRules === true;
         const timerActive = options.activeTimerRuleIndices
@@ -6,6 +6,8 @@ import { ThemeRule } from "./types";
 import {
     getPatternMatches,
     getRuleConditionKey,
+    getScheduleKey,
+    isWellFormedRule,
     patternsActuallyOverlap,
     rulesOverlap,
 } from "./ruleOverlap";
@@ -55,6 +57,7 @@ export interface LintResult {
 }
 
 // * main linting function - runs all checks
+// ? malformed settings entries are skipped by every check (see isWellFormedRule)
 export async function lintRules(rules: ThemeRule[]): Promise<LintResult> {
     const issues: LintIssue[] = [];
 
@@ -88,12 +91,15 @@ export async function lintRules(rules: ThemeRule[]): Promise<LintResult> {
 }
 
 // * find exact duplicate rules
-function findExactDuplicates(rules: ThemeRule[]): LintIssue[] {
+export function findExactDuplicates(rules: ThemeRule[]): LintIssue[] {
     const issues: LintIssue[] = [];
     const seen = new Map<string, number>();
 
     for (let i = 0; i < rules.length; i++) {
         const rule = rules[i];
+        if (!isWellFormedRule(rule)) {
+            continue;
+        }
         const key = `${getRuleConditionKey(rule)}|||${rule.theme}`;
 
         if (seen.has(key)) {
@@ -125,11 +131,17 @@ function findUnreachableRules(rules: ThemeRule[]): LintIssue[] {
 
     for (let i = 1; i < rules.length; i++) {
         const currentRule = rules[i];
+        if (!isWellFormedRule(currentRule)) {
+            continue;
+        }
         const currentIsTimer = currentRule.when.timerInterval !== undefined;
 
         // check if any earlier rule makes this one unreachable
         for (let j = 0; j < i; j++) {
             const earlierRule = rules[j];
+            if (!isWellFormedRule(earlierRule)) {
+                continue;
+            }
             const earlierIsTimer = earlierRule.when.timerInterval !== undefined;
 
             // timer rules should only be compared against other timer rules
@@ -180,6 +192,9 @@ function isRuleShadowedBy(targetRule: ThemeRule, shadowingRule: ThemeRule): bool
         target.debugType,
         target.testState,
         target.viewMode,
+        target.timeOfDay,
+        target.schedule,
+        ...Object.keys(target.custom ?? {}),
         target.timerInterval !== undefined ? target.timerInterval : null,
     ].filter((v) => v !== null && v !== undefined).length;
 
@@ -191,6 +206,9 @@ function isRuleShadowedBy(targetRule: ThemeRule, shadowingRule: ThemeRule): bool
         shadow.debugType,
         shadow.testState,
         shadow.viewMode,
+        shadow.timeOfDay,
+        shadow.schedule,
+        ...Object.keys(shadow.custom ?? {}),
         shadow.timerInterval !== undefined ? shadow.timerInterval : null,
     ].filter((v) => v !== null && v !== undefined).length;
 
@@ -225,7 +243,7 @@ function isRuleShadowedBy(targetRule: ThemeRule, shadowingRule: ThemeRule): bool
     // target is MORE SPECIFIC file-wise, but still SHADOWED by the more general shadow.
     // This is different from context conditions below!
 
-    // CONTEXT conditions (debug, test, view, timer): define WHEN rules apply
+    // CONTEXT conditions (debug, test, view, time of day, schedule, custom, timer): define WHEN rules apply
     // if target has extra context constraints, it's more specific TIME-wise and NOT shadowed
     // e.g., shadow={ language: "typescript" } does NOT shadow target={ language: "typescript", debugSession: "active" }
 
@@ -267,6 +285,33 @@ function isRuleShadowedBy(targetRule: ThemeRule, shadowingRule: ThemeRule): bool
         return false;
     }
 
+    if (shadow.timeOfDay) {
+        if (target.timeOfDay !== shadow.timeOfDay) {
+            return false;
+        }
+    } else if (target.timeOfDay) {
+        return false;
+    }
+
+    const shadowSchedule = getScheduleKey(shadow);
+    if (shadowSchedule) {
+        if (getScheduleKey(target) !== shadowSchedule) {
+            return false;
+        }
+    } else if (getScheduleKey(target)) {
+        return false;
+    }
+
+    // shadow's custom conditions must all be required by target, & target may not add more
+    const shadowCustom = shadow.custom ?? {};
+    const targetCustom = target.custom ?? {};
+    if (Object.keys(shadowCustom).some((id) => targetCustom[id] !== shadowCustom[id])) {
+        return false;
+    }
+    if (Object.keys(targetCustom).some((id) => shadowCustom[id] === undefined)) {
+        return false;
+    }
+
     if (shadow.timerInterval !== undefined) {
         if (target.timerInterval !== shadow.timerInterval) {
             return false;
@@ -309,6 +354,9 @@ function findInvalidPatterns(rules: ThemeRule[]): LintIssue[] {
 
     for (let i = 0; i < rules.length; i++) {
         const rule = rules[i];
+        if (!isWellFormedRule(rule)) {
+            continue;
+        }
         if (rule.when.pattern) {
             const validationError = validateGlobPattern(rule.when.pattern);
             if (validationError) {
@@ -349,6 +397,9 @@ async function findInvalidLanguages(rules: ThemeRule[]): Promise<LintIssue[]> {
 
     for (let i = 0; i < rules.length; i++) {
         const rule = rules[i];
+        if (!isWellFormedRule(rule)) {
+            continue;
+        }
         if (rule.when.language) {
             const langId = rule.when.language.toLowerCase();
             if (!knownLanguages.has(langId)) {
@@ -373,6 +424,9 @@ function findMissingThemes(rules: ThemeRule[]): LintIssue[] {
 
     for (let i = 0; i < rules.length; i++) {
         const rule = rules[i];
+        if (!isWellFormedRule(rule)) {
+            continue;
+        }
         // check both theme ID and label (like validateInstalledTheme in themeCatalog.ts)
         const themeExists = installedThemes.some(
             (theme) => theme.id === rule.theme || theme.label === rule.theme
@@ -399,10 +453,16 @@ function generateReorderingSuggestions(rules: ThemeRule[]): LintIssue[] {
     // suggestion: more specific rules should come before more general ones
     for (let i = 1; i < rules.length; i++) {
         const currentRule = rules[i];
+        if (!isWellFormedRule(currentRule)) {
+            continue;
+        }
         const currentSpecificity = getRuleSpecificity(currentRule);
 
         for (let j = 0; j < i; j++) {
             const earlierRule = rules[j];
+            if (!isWellFormedRule(earlierRule)) {
+                continue;
+            }
             const earlierSpecificity = getRuleSpecificity(earlierRule);
 
             // if current rule is more specific than an earlier rule, suggest reordering
@@ -2,7 +2,8 @@
 // Helpers for detecting overlapping rules
 
 import { minimatch } from "minimatch";
-import { ThemeRule } from "./types";
+import { RuleCondition, ThemeRule } from "./types";
+import { cronSchedulesOverlap, validateCronExpression } from "./utils/cron";
 
 const LANGUAGE_EXTENSIONS: Record<string, string[]> = {
     typescript: [".ts"],
@@ -44,8 +45,78 @@ const LANGUAGE_EXTENSIONS: Record<string, string[]> = {
     clojure: [".clj", ".cljs", ".cljc"],
 };
 
-export function getRuleConditionKey(rule: ThemeRule): string {
+// stable key for custom conditions regardless of property order
+function formatCustomKey(custom: Record<string, string> | undefined): string {
+    if (!custom) {
+        return "";
+    }
+    return Object.keys(custom)
+        .sort()
+        .map((id) => `${id}=${custom[id]}`)
+        .join(",");
+}
+
+// custom conditions conflict only when both rules expect different values for the same provider
+function customConditionsCompatible(
+    customA: Record<string, string> | undefined,
+    customB: Record<string, string> | undefined
+): boolean {
+    if (!customA || !customB) {
+        return true;
+    }
+    return Object.keys(customA).every(
+        (id) => customB[id] === undefined || customB[id] === customA[id]
+    );
+}
+
+const STRING_CONDITIONS = [
+    "language",
+    "pattern",
+    "workspaceName",
+    "debugSession",
+    "debugType",
+    "testState",
+    "viewMode",
+    "timeOfDay",
+    "schedule",
+] as const;
+
+// * true when a settings entry has the declared rule shape (values may still be invalid)
+// overlap & lint checks skip anything else; validateConfig reports it
+export function isWellFormedRule(rule: ThemeRule): boolean {
+    if (!rule || typeof rule !== "object" || !rule.when || typeof rule.when !== "object") {
+        return false;
+    }
+
     const when = rule.when;
+    const custom = when.custom as unknown;
+    return (
+        !Array.isArray(when) &&
+        STRING_CONDITIONS.every((key) => when[key] === undefined || typeof when[key] === "string") &&
+        (when.timerInterval === undefined || typeof when.timerInterval === "number") &&
+        (custom === undefined ||
+            (Boolean(custom) && typeof custom === "object" && !Array.isArray(custom)))
+    );
+}
+
+// trimmed cron expression, ignoring non-string values from settings
+export function getScheduleKey(when: RuleCondition): string {
+    return typeof when.schedule === "string" ? when.schedule.trim() : "";
+}
+
+// schedules overlap when they share a minute; unparseable ones only match themselves
+function schedulesCompatible(scheduleA: string, scheduleB: string): boolean {
+    if (!scheduleA || !scheduleB || scheduleA === scheduleB) {
+        return true;
+    }
+    if (validateCronExpression(scheduleA) || validateCronExpression(scheduleB)) {
+        return false;
+    }
+    return cronSchedulesOverlap(scheduleA, scheduleB);
+}
+
+export function getRuleConditionKey(rule: ThemeRule): string {
+    const when: RuleCondition = isWellFormedRule(rule) ? rule.when : {};
     return [
         when.language ?? "",
         when.pattern ?? "",
@@ -55,6 +126,9 @@ export function getRuleConditionKey(rule: ThemeRule): string {
         when.testState ?? "",
         when.timerInterval?.toString() ?? "",
         when.viewMode ?? "",
+        when.timeOfDay ?? "",
+        getScheduleKey(when),
+        formatCustomKey(when.custom),
     ].join("|||");
 }
 
@@ -121,7 +195,7 @@ function generateSamplePaths(language?: string): string[] {
 
 // * determine if two rules overlap in effect
 export function rulesOverlap(ruleA: ThemeRule, ruleB: ThemeRule): boolean {
-    if (ruleA === ruleB) {
+    if (ruleA === ruleB || !isWellFormedRule(ruleA) || !isWellFormedRule(ruleB)) {
         return false;
     }
 
@@ -157,7 +231,10 @@ export function rulesOverlap(ruleA: ThemeRule, ruleB: ThemeRule): boolean {
         (!whenA.timerInterval ||
             !whenB.timerInterval ||
             whenA.timerInterval === whenB.timerInterval) &&
-        (!whenA.viewMode || !whenB.viewMode || whenA.viewMode === whenB.viewMode);
+        (!whenA.viewMode || !whenB.viewMode || whenA.viewMode === whenB.viewMode) &&
+        (!whenA.timeOfDay || !whenB.timeOfDay || whenA.timeOfDay === whenB.timeOfDay) &&
+        schedulesCompatible(getScheduleKey(whenA), getScheduleKey(whenB)) &&
+        customConditionsCompatible(whenA.custom, whenB.custom);
 
     if (!contextsCompatible) {
         return false;
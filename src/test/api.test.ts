@@ -0,0 +1,54 @@
+// src/test/api.test.ts
+// Tests for the public extension API
+
+import * as assert from "assert";
+import * as vscode from "vscode";
+import { createExtensionApi } from "../api";
+import { ContextManager } from "../contextManager";
+import { createTestThemeManager } from "./testUtils";
+
+suite("Extension API", () => {
+    test("custom context providers publish values into the context", () => {
+        const manager = new ContextManager();
+        const api = createExtensionApi(manager, createTestThemeManager());
+        const emitter = new vscode.EventEmitter<string | undefined>();
+
+        const registration = api.registerContextProvider("ci.status", {
+            getValue: () => "passing",
+            onDidChange: emitter.event,
+        });
+        assert.deepStrictEqual(manager.getContext().custom, { "ci.status": "passing" });
+        assert.deepStrictEqual(api.getContextProviderIds(), ["ci.status"]);
+
+        emitter.fire("failing");
+        assert.deepStrictEqual(manager.getContext().custom, { "ci.status": "failing" });
+
+        registration.dispose();
+        assert.deepStrictEqual(manager.getContext().custom, {});
+        assert.deepStrictEqual(api.getContextProviderIds(), []);
+
+        // events after unregistering are ignored
+        emitter.fire("passing");
+        assert.deepStrictEqual(manager.getContext().custom, {});
+
+        emitter.dispose();
+        manager.dispose();
+    });
+
+    test("rejects duplicate & malformed provider ids", () => {
+        const manager = new ContextManager();
+        const api = createExtensionApi(manager, createTestThemeManager());
+        const emitter = new vscode.EventEmitter<string | undefined>();
+        const provider = { getValue: () => undefined, onDidChange: emitter.event };
+
+        const registration = api.registerContextProvider("focus", provider);
+        assert.throws(() => api.registerContextProvider("focus", provider), /already registered/);
+        assert.throws(() => api.registerContextProvider("bad id", provider), /Invalid/);
+
+        registration.dispose();
+        assert.doesNotThrow(() => api.registerContextProvider("focus", provider).dispose());
+
+        emitter.dispose();
+        manager.dispose();
+    });
+});
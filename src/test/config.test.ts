@@ -0,0 +1,221 @@
+// src/test/config.test.ts
+// Tests for configuration helpers
+
+import * as assert from "assert";
+import * as fs from "fs";
+import * as path from "path";
+import {
+    getActiveRuleSet,
+    getEnvironmentOverride,
+    getPrecedence,
+    resolvePrecedence,
+    validateConfig,
+} from "../config";
+import { ReactiveThemesConfig, ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
+
+suite("Config", () => {
+    suite("getEnvironmentOverride", () => {
+        test("REACTIVE_THEME forces a theme", () => {
+            assert.deepStrictEqual(getEnvironmentOverride({ REACTIVE_THEME: " Monokai " }), {
+                theme: "Monokai",
+                source: "REACTIVE_THEME",
+            });
+        });
+
+        test("REACTIVE_THEME wins over REACTIVE_THEME_MODE", () => {
+            const override = getEnvironmentOverride({
+                REACTIVE_THEME: "Monokai",
+                REACTIVE_THEME_MODE: "light",
+            });
+            assert.strictEqual(override?.source, "REACTIVE_THEME");
+        });
+
+        test("auto, unknown, & missing modes leave rules in charge", () => {
+            assert.strictEqual(getEnvironmentOverride({}), undefined);
+            assert.strictEqual(getEnvironmentOverride({ REACTIVE_THEME: "  " }), undefined);
+            assert.strictEqual(getEnvironmentOverride({ REACTIVE_THEME_MODE: "auto" }), undefined);
+            assert.strictEqual(getEnvironmentOverride({ REACTIVE_THEME_MODE: "sepia" }), undefined);
+        });
+    });
+
+    suite("getActiveRuleSet", () => {
+        const globalRule: ThemeRule = { name: "md", when: { language: "markdown" }, theme: "A" };
+        const profileRule: ThemeRule = { name: "ts", when: { language: "typescript" }, theme: "B" };
+        const baseConfig: ReactiveThemesConfig = {
+            enabled: true,
+            rules: [globalRule],
+            defaultTheme: "Default",
+            debounceMs: 0,
+            profiles: {
+                work: { rules: [profileRule] },
+                presentation: { rules: [], defaultTheme: "High Contrast" },
+                night: { defaultTheme: "Night" },
+            },
+        };
+
+        test("uses top-level rules without an active profile", () => {
+            assert.deepStrictEqual(getActiveRuleSet(baseConfig), {
+                rules: [globalRule],
+                defaultTheme: "Default",
+                setting: "reactiveThemes.rules",
+            });
+            const unknown = getActiveRuleSet({ ...baseConfig, activeProfile: "missing" });
+            assert.strictEqual(unknown.profile, undefined);
+            assert.deepStrictEqual(unknown.rules, [globalRule]);
+        });
+
+        test("inherited object keys are not profiles", () => {
+            for (const name of ["toString", "constructor", "__proto__"]) {
+                const ruleSet = getActiveRuleSet({ ...baseConfig, activeProfile: name });
+                assert.strictEqual(ruleSet.profile, undefined, name);
+                assert.strictEqual(ruleSet.setting, "reactiveThemes.rules", name);
+
+                const result = validateConfig({ ...baseConfig, activeProfile: name });
+                assert.ok(
+                    result.errors.includes(
+                        `reactiveThemes.activeProfile "${name}" is not defined in reactiveThemes.profiles`
+                    ),
+                    name
+                );
+            }
+        });
+
+        test("profile rules & default theme replace top-level settings", () => {
+            const work = getActiveRuleSet({ ...baseConfig, activeProfile: "work" });
+            assert.deepStrictEqual(work.rules, [profileRule]);
+            assert.strictEqual(work.defaultTheme, "Default");
+            assert.strictEqual(work.setting, "reactiveThemes.profiles.work.rules");
+
+            const presentation = getActiveRuleSet({ ...baseConfig, activeProfile: "presentation" });
+            assert.deepStrictEqual(presentation.rules, []);
+            assert.strictEqual(presentation.defaultTheme, "High Contrast");
+
+            // a profile without rules keeps the top-level rules
+            const night = getActiveRuleSet({ ...baseConfig, activeProfile: "night" });
+            assert.deepStrictEqual(night.rules, [globalRule]);
+            assert.strictEqual(night.defaultTheme, "Night");
+            assert.strictEqual(night.setting, "reactiveThemes.rules");
+        });
+    });
+
+    suite("precedence", () => {
+        const config: ReactiveThemesConfig = { enabled: true, rules: [], debounceMs: 0 };
+
+        test("defaults to environment > manual > rules", () => {
+            assert.deepStrictEqual(getPrecedence(config), ["environment", "manual", "rules"]);
+            assert.strictEqual(
+                resolvePrecedence(config, { environment: true, manual: true, rules: true }),
+                "environment"
+            );
+            assert.strictEqual(resolvePrecedence(config, { manual: true, rules: true }), "manual");
+            assert.strictEqual(resolvePrecedence(config, {}), undefined);
+        });
+
+        test("configured order wins & unlisted sources follow in default order", () => {
+            const rulesFirst = { ...config, precedence: ["rules" as const] };
+            assert.deepStrictEqual(getPrecedence(rulesFirst), ["rules", "environment", "manual"]);
+            assert.strictEqual(
+                resolvePrecedence(rulesFirst, { environment: true, manual: true, rules: true }),
+                "rules"
+            );
+            assert.strictEqual(resolvePrecedence(rulesFirst, { manual: true }), "manual");
+        });
+
+        test("invalid entries are ignored at runtime & reported by validation", () => {
+            const precedence = ["manual", "sometimes", "manual"] as unknown;
+            const invalid = {
+                ...config,
+                precedence: precedence as ReactiveThemesConfig["precedence"],
+            };
+            assert.deepStrictEqual(getPrecedence(invalid), ["manual", "environment", "rules"]);
+
+            const result = validateConfig(invalid);
+            assert.strictEqual(result.valid, false);
+            assert.ok(result.errors.some((error) => error.includes("unknown sources: sometimes")));
+            assert.ok(
+                result.errors.includes("reactiveThemes.precedence lists a source more than once")
+            );
+        });
+    });
+
+    suite("validateConfig", () => {
+        const typoRule = {
+            name: "Typo",
+            when: { langauge: "markdown", pattern: "**/*.md" },
+            theme: "A",
+            colour: "red",
+        } as unknown as ThemeRule;
+        const config: ReactiveThemesConfig = { enabled: true, rules: [typoRule], debounceMs: 0 };
+
+        test("unknown keys are warnings in lenient mode & errors in strict mode", () => {
+            const lenient = validateConfig(config);
+            assert.deepStrictEqual(lenient.warnings, [
+                "Rule at index 0 has unknown keys: colour, when.langauge",
+            ]);
+            assert.ok(!lenient.errors.some((error) => error.includes("unknown keys")));
+
+            const strict = validateConfig({ ...config, validation: "strict" });
+            assert.deepStrictEqual(strict.warnings, []);
+            assert.ok(
+                strict.errors.includes("Rule at index 0 has unknown keys: colour, when.langauge")
+            );
+            assert.strictEqual(strict.valid, false);
+        });
+
+        test("strict mode rejects exact duplicate rules", () => {
+            const rule: ThemeRule = { name: "md", when: { language: "markdown" }, theme: "A" };
+            const copy: ThemeRule = { ...rule, name: "md again" };
+            const duplicates: ReactiveThemesConfig = {
+                enabled: true,
+                rules: [rule, copy],
+                debounceMs: 0,
+                profiles: { work: { rules: [copy, rule] } },
+            };
+            const isDuplicateError = (error: string) => error.includes("Exact duplicate");
+
+            assert.ok(!validateConfig(duplicates).errors.some(isDuplicateError));
+
+            const strict = validateConfig({ ...duplicates, validation: "strict" });
+            assert.strictEqual(strict.valid, false);
+            assert.deepStrictEqual(strict.errors.filter(isDuplicateError), [
+                'Rule at index 1: Exact duplicate of rule #1 "md"',
+                'Profile "work": Rule at index 1: Exact duplicate of rule #1 "md again"',
+            ]);
+        });
+
+        test("reports malformed settings instead of throwing", () => {
+            const rules = createMalformedRules();
+            const profiles: unknown = { junk: null, list: { rules: [null, 7] } };
+
+            const result = validateConfig({
+                enabled: true,
+                rules,
+                debounceMs: 0,
+                profiles: profiles as ReactiveThemesConfig["profiles"],
+            });
+            assert.strictEqual(result.valid, false);
+            for (let index = 0; index < rules.length; index++) {
+                assert.ok(
+                    result.errors.some((error) => error.startsWith(`Rule at index ${index} `)),
+                    `rule ${index} should be reported`
+                );
+            }
+            assert.ok(result.errors.includes('Profile "junk" must be an object'));
+        });
+    });
+
+    suite("settings schema", () => {
+        // ? package.json can't share a definition, so profiles.*.rules repeats the rule schema
+        test("profile rules use the same item schema as reactiveThemes.rules", () => {
+            const manifest = JSON.parse(
+                fs.readFileSync(path.join(__dirname, "..", "..", "package.json"), "utf8")
+            );
+            const properties = manifest.contributes.configuration.properties;
+            assert.deepStrictEqual(
+                properties["reactiveThemes.profiles"].additionalProperties.properties.rules.items,
+                properties["reactiveThemes.rules"].items
+            );
+        });
+    });
+});
@@ -0,0 +1,159 @@
+// src/test/cron.test.ts
+// Tests for cron expression parsing & matching
+
+import * as assert from "assert";
+import {
+    CronSchedule,
+    cronMatches,
+    cronSchedulesOverlap,
+    parseCronExpression,
+    validateCronExpression,
+} from "../utils/cron";
+import { createSeededRandom } from "./testUtils";
+
+// local time helper (month is 1-based)
+function at(year: number, month: number, day: number, hours: number, minutes: number): Date {
+    return new Date(year, month - 1, day, hours, minutes);
+}
+
+suite("Cron", () => {
+    test("matches wildcard, range, & list fields", () => {
+        // 2025-01-06 is a Monday
+        assert.ok(cronMatches("* * * * *", at(2025, 1, 6, 3, 17)));
+        assert.ok(cronMatches("* 9-16 * * 1-5", at(2025, 1, 6, 9, 0)));
+        assert.ok(cronMatches("* 9-16 * * 1-5", at(2025, 1, 6, 16, 59)));
+        assert.ok(!cronMatches("* 9-16 * * 1-5", at(2025, 1, 6, 17, 0)));
+        assert.ok(!cronMatches("* 9-16 * * 1-5", at(2025, 1, 5, 10, 0)));
+        assert.ok(cronMatches("0,30 * * * *", at(2025, 1, 6, 10, 30)));
+        assert.ok(!cronMatches("0,30 * * * *", at(2025, 1, 6, 10, 15)));
+    });
+
+    test("supports steps & offset steps", () => {
+        assert.ok(cronMatches("*/15 * * * *", at(2025, 3, 1, 8, 45)));
+        assert.ok(!cronMatches("*/15 * * * *", at(2025, 3, 1, 8, 50)));
+        assert.ok(cronMatches("5/20 * * * *", at(2025, 3, 1, 8, 45)));
+        assert.ok(cronMatches("0-30/10 * * * *", at(2025, 3, 1, 8, 30)));
+        assert.ok(!cronMatches("0-30/10 * * * *", at(2025, 3, 1, 8, 40)));
+    });
+
+    test("supports month & day names & Sunday as 7", () => {
+        assert.ok(cronMatches("* * * dec *", at(2025, 12, 24, 12, 0)));
+        assert.ok(cronMatches("* * * * sat,sun", at(2025, 1, 5, 12, 0)));
+        assert.ok(cronMatches("* * * * 7", at(2025, 1, 5, 12, 0)));
+        assert.ok(cronMatches("* * * * MON-FRI", at(2025, 1, 10, 12, 0)));
+    });
+
+    test("uses OR semantics when both day fields are restricted", () => {
+        // 1st of the month OR Friday
+        assert.ok(cronMatches("* * 1 * 5", at(2025, 1, 1, 12, 0)));
+        assert.ok(cronMatches("* * 1 * 5", at(2025, 1, 10, 12, 0)));
+        assert.ok(!cronMatches("* * 1 * 5", at(2025, 1, 8, 12, 0)));
+        // wildcard day-of-week still restricts to day-of-month only
+        assert.ok(!cronMatches("* * 1 * *", at(2025, 1, 2, 12, 0)));
+    });
+
+    test("normalizes whitespace & caches parsed schedules", () => {
+        assert.strictEqual(parseCronExpression("  0  9 * * 1 "), parseCronExpression("0 9 * * 1"));
+    });
+
+    test("reports descriptive validation errors", () => {
+        assert.strictEqual(validateCronExpression("* 9-16 * * 1-5"), undefined);
+        assert.strictEqual(validateCronExpression("  "), "Schedule cannot be empty");
+        assert.ok(validateCronExpression("* * *")?.includes("Expected 5 fields"));
+        assert.ok(validateCronExpression("60 * * * *")?.includes("out of range"));
+        assert.ok(validateCronExpression("* 17-9 * * *")?.includes("reversed"));
+        assert.ok(validateCronExpression("*/0 * * * *")?.includes("Invalid step"));
+        assert.ok(validateCronExpression("* * * foo *")?.includes("Invalid month"));
+    });
+
+    test("detects schedules that share a minute", () => {
+        assert.ok(cronSchedulesOverlap("* 9-17 * * 1-5", "* 12-13 * * *"));
+        assert.ok(cronSchedulesOverlap("*/15 * * * *", "30 * * * *"));
+        assert.ok(!cronSchedulesOverlap("*/15 * * * *", "5 * * * *"));
+        assert.ok(!cronSchedulesOverlap("* 9-17 * * 1-5", "* * * * 0,6"));
+        assert.ok(!cronSchedulesOverlap("0 9 * jan *", "0 9 * feb *"));
+    });
+
+    test("schedule overlap follows the either-day rule", () => {
+        // the 1st is sometimes a Sunday
+        assert.ok(cronSchedulesOverlap("0 9 1 * *", "0 9 * * sun"));
+        // "1st or Monday" includes every Monday
+        assert.ok(cronSchedulesOverlap("0 9 1 * mon", "0 9 * * 1"));
+        assert.ok(!cronSchedulesOverlap("0 9 * * mon", "0 9 * * tue"));
+    });
+
+    // ? property-style: generated input either parses into in-range sets or fails w/ a plain Error
+    test("generated expressions parse in range or fail descriptively", () => {
+        const random = createSeededRandom(97);
+        const pick = <T>(items: T[]): T => items[random(items.length)];
+        const tokens = ["*", "0", "7", "59", "60", "mon", "jan", "foo", "-", ",", "/", " ", "\t"];
+        const bounds: Array<[number, number]> = [
+            [0, 59],
+            [0, 23],
+            [1, 31],
+            [1, 12],
+            [0, 6],
+        ];
+
+        // well-structured fields w/ values near each field's range exercise the success path too
+        const field = (_: unknown, index: number): string => {
+            const [min, max] = bounds[index];
+            const value = () => String(min + random(max - min + 3));
+            switch (random(6)) {
+                case 0:
+                    return "*";
+                case 1:
+                    return value();
+                case 2:
+                    return `${value()}-${value()}`;
+                case 3:
+                    return `*/${random(20)}`;
+                case 4:
+                    return `${value()}-${value()}/${random(20)}`;
+                default:
+                    return `${value()},${value()}`;
+            }
+        };
+
+        let parsed = 0;
+        for (let i = 0; i < 500; i++) {
+            const expression =
+                i % 2 === 0
+                    ? Array.from({ length: 5 }, field).join(" ")
+                    : Array.from({ length: random(12) }, () => pick(tokens)).join("");
+
+            let schedule: CronSchedule;
+            try {
+                schedule = parseCronExpression(expression);
+            } catch (error) {
+                assert.ok(error instanceof Error, `"${expression}" threw a non-Error`);
+                assert.strictEqual(error.constructor, Error, `"${expression}": ${error.message}`);
+                assert.ok(error.message.length > 0);
+                assert.strictEqual(
+                    validateCronExpression(expression),
+                    expression.trim() ? error.message : "Schedule cannot be empty"
+                );
+                continue;
+            }
+
+            parsed++;
+            const sets = [
+                schedule.minutes,
+                schedule.hours,
+                schedule.daysOfMonth,
+                schedule.months,
+                schedule.daysOfWeek,
+            ];
+            sets.forEach((values, index) => {
+                const [min, max] = bounds[index];
+                assert.ok(values.size > 0, `"${expression}" field ${index} is empty`);
+                values.forEach((value) =>
+                    assert.ok(value >= min && value <= max, `"${expression}" produced ${value}`)
+                );
+            });
+            assert.strictEqual(validateCronExpression(expression), undefined);
+            cronMatches(expression, at(2025, 1, 6, 9, 30));
+        }
+        assert.ok(parsed > 0, "no generated expression parsed");
+    });
+});
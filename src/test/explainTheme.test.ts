@@ -2,6 +2,9 @@
 // Tests for explainTheme command logic
 
 import * as assert from "assert";
+import { evaluateRulesForExplanation } from "../commands/explainTheme";
+import { ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
 
 suite("Explain Theme Command", () => {
     // note: explainTheme is highly integrated with VSCode API (activeTextEditor,
@@ -13,6 +16,40 @@ suite("Explain Theme Command", () => {
     // bucketing, markdown formatting) from VSCode API calls, then test pure functions
 
     suite("rule evaluation bucketing", () => {
+        const fileContext = { languageId: "markdown", filePath: "/repo/docs/README.md" };
+
+        test("first match wins & later matches are shadowed", () => {
+            const rules: ThemeRule[] = [
+                { name: "ts", when: { language: "typescript" }, theme: "A" },
+                { name: "md", when: { language: "markdown" }, theme: "B" },
+                { name: "docs", when: { pattern: "**/docs/**" }, theme: "C" },
+            ];
+
+            const { evaluations, winnerIndex } = evaluateRulesForExplanation(rules, fileContext, {});
+            assert.strictEqual(winnerIndex, 1);
+            assert.deepStrictEqual(
+                evaluations.map((evaluation) => evaluation.matched),
+                [false, true, true]
+            );
+        });
+
+        test("malformed settings entries never match & keep their index", () => {
+            const good: ThemeRule = { name: "md", when: { language: "markdown" }, theme: "B" };
+            const rules = [...createMalformedRules(), good];
+
+            const { evaluations, winnerIndex } = evaluateRulesForExplanation(rules, fileContext, {});
+            assert.strictEqual(winnerIndex, rules.length - 1);
+            assert.deepStrictEqual(
+                evaluations.map((evaluation) => evaluation.index),
+                rules.map((_, index) => index)
+            );
+            for (const evaluation of evaluations.slice(0, -1)) {
+                assert.strictEqual(evaluation.matched, false);
+                assert.strictEqual(typeof evaluation.rule.name, "string");
+                assert.deepStrictEqual(evaluation.conditions, []);
+            }
+        });
+
         test("placeholder for matched rules tests", function () {
             this.skip(); // requires mock setup
         });
@@ -1,7 +1,11 @@
 import * as assert from "assert";
 import { evaluateRules } from "../../ruleEngine";
-import { ThemeManager } from "../../themeManager";
-import { createMockContext, createMockEditor, createMockRule } from "../testUtils";
+import {
+    createMockContext,
+    createMockEditor,
+    createMockRule,
+    createTestThemeManager,
+} from "../testUtils";
 
 suite("Integration - Rule Evaluation to Theme Application", () => {
     test("applies matched theme via ThemeManager", async () => {
@@ -13,13 +17,11 @@ suite("Integration - Rule Evaluation to Theme Application", () => {
         assert.ok(result.matched, "Expected rule to match");
 
         const appliedThemes: string[] = [];
-        const manager = new ThemeManager(
-            0,
-            async (theme: string) => {
+        const manager = createTestThemeManager({
+            setTheme: async (theme: string) => {
                 appliedThemes.push(theme);
             },
-            () => "original-theme"
-        );
+        });
 
         if (result.matched && result.theme) {
             manager.applyTheme(result.theme, "integration-test");
@@ -103,4 +103,123 @@ suite("Rule Engine", () => {
         assert.ok(activeResult.matched);
         assert.strictEqual(activeResult.theme, "TimerTheme");
     });
+
+    test("time-of-day rules match the current solar phase", () => {
+        const rules: ThemeRule[] = [
+            { name: "Night", when: { timeOfDay: "night" }, theme: "NightTheme" },
+            { name: "Day", when: { timeOfDay: "day" }, theme: "DayTheme" },
+        ];
+
+        const editor = {
+            document: {
+                languageId: "typescript",
+                uri: vscode.Uri.file("/workspace/src/main.ts"),
+            },
+        } as unknown as vscode.TextEditor;
+
+        const context = {
+            debugSession: "inactive" as const,
+            testState: "none" as const,
+            viewMode: "normal" as const,
+            timerTick: 0,
+        };
+
+        const dayResult = evaluateRules(rules, editor, { ...context, timeOfDay: "day" });
+        assert.strictEqual(dayResult.theme, "DayTheme");
+
+        const nightResult = evaluateRules(rules, editor, { ...context, timeOfDay: "night" });
+        assert.strictEqual(nightResult.theme, "NightTheme");
+
+        // without a configured location the phase is unknown & nothing matches
+        const unknownResult = evaluateRules(rules, editor, context);
+        assert.strictEqual(unknownResult.matched, false);
+    });
+
+    test("schedule rules match only while their expression is active", () => {
+        const rules: ThemeRule[] = [
+            { name: "Late night", when: { schedule: "* 0-5 * * *" }, theme: "NightTheme" },
+            { name: "Work hours", when: { schedule: " * 9-16 * * 1-5 " }, theme: "WorkTheme" },
+        ];
+
+        const editor = {
+            document: {
+                languageId: "typescript",
+                uri: vscode.Uri.file("/workspace/src/main.ts"),
+            },
+        } as unknown as vscode.TextEditor;
+
+        const context = {
+            debugSession: "inactive" as const,
+            testState: "none" as const,
+            viewMode: "normal" as const,
+            timerTick: 0,
+        };
+
+        const workResult = evaluateRules(rules, editor, {
+            ...context,
+            activeSchedules: ["* 9-16 * * 1-5"],
+        });
+        assert.strictEqual(workResult.theme, "WorkTheme");
+
+        const idleResult = evaluateRules(rules, editor, { ...context, activeSchedules: [] });
+        assert.strictEqual(idleResult.matched, false);
+    });
+
+    test("custom rules require every provider value to match", () => {
+        const rules: ThemeRule[] = [
+            {
+                name: "CI red on main",
+                when: { custom: { "ci.status": "failing", branch: "main" } },
+                theme: "RedTheme",
+            },
+        ];
+
+        const editor = {
+            document: {
+                languageId: "typescript",
+                uri: vscode.Uri.file("/workspace/src/main.ts"),
+            },
+        } as unknown as vscode.TextEditor;
+
+        const context = {
+            debugSession: "inactive" as const,
+            testState: "none" as const,
+            viewMode: "normal" as const,
+            timerTick: 0,
+        };
+
+        const matching = evaluateRules(rules, editor, {
+            ...context,
+            custom: { "ci.status": "failing", branch: "main" },
+        });
+        assert.strictEqual(matching.theme, "RedTheme");
+
+        const partial = evaluateRules(rules, editor, {
+            ...context,
+            custom: { "ci.status": "failing" },
+        });
+        assert.strictEqual(partial.matched, false);
+        assert.strictEqual(evaluateRules(rules, editor, context).matched, false);
+    });
+
+    test("skips malformed rules from settings without throwing", () => {
+        const rules = [
+            null,
+            { name: "No when", theme: "A" },
+            { name: "Bad schedule", when: { schedule: 5 }, theme: "B" },
+            { name: "Null custom", when: { custom: null }, theme: "C" },
+            { name: "Bad pattern", when: { pattern: 42 }, theme: "D" },
+            { name: "Valid", when: { language: "typescript" }, theme: "E" },
+        ] as unknown as ThemeRule[];
+
+        const editor = {
+            document: {
+                languageId: "typescript",
+                uri: vscode.Uri.file("/workspace/src/main.ts"),
+            },
+        } as unknown as vscode.TextEditor;
+
+        const result = evaluateRules(rules, editor, {});
+        assert.strictEqual(result.theme, "E");
+    });
 });
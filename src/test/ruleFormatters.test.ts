@@ -9,6 +9,7 @@ import {
 	formatRuleAsMarkdown,
 } from "../utils/ruleFormatters";
 import { ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
 
 suite("Rule Formatters", () => {
 	suite("formatRuleConditions", () => {
@@ -194,6 +195,13 @@ suite("Rule Formatters", () => {
 			const result = formatRuleConditions(rule, { mode: "markdown" });
 			assert.strictEqual(result, "");
 		});
+
+		test("malformed settings entries format as no conditions", () => {
+			for (const rule of createMalformedRules()) {
+				assert.strictEqual(formatRuleConditions(rule, { mode: "compact" }), "");
+				assert.deepStrictEqual(formatRuleConditions(rule, { mode: "equality" }), []);
+			}
+		});
 	});
 
 	suite("formatRuleForQuickPick", () => {
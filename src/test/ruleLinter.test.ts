@@ -2,6 +2,7 @@ import * as assert from "assert";
 import * as vscode from "vscode";
 import { lintRules, groupIssuesByType, getIssueTypeLabel, getSeverityIcon } from "../ruleLinter";
 import { ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
 
 suite("Rule Linter", () => {
     suite("Exact Duplicate Detection", () => {
@@ -219,6 +220,87 @@ suite("Rule Linter", () => {
             assert.strictEqual(unreachable.length, 1);
             assert.strictEqual(unreachable[0].ruleIndex, 1);
         });
+
+        test("considers time of day in shadowing detection", async () => {
+            const general = await lintRules([
+                { name: "All TypeScript", when: { language: "typescript" }, theme: "Dark" },
+                {
+                    name: "TypeScript Night",
+                    when: { language: "typescript", timeOfDay: "night" },
+                    theme: "Darker",
+                },
+            ]);
+            assert.strictEqual(general.issues.filter((i) => i.type === "unreachable").length, 0);
+
+            const sameTime = await lintRules([
+                { name: "Night", when: { language: "typescript", timeOfDay: "night" }, theme: "A" },
+                {
+                    name: "Night Tests",
+                    when: { language: "typescript", pattern: "**/*.test.ts", timeOfDay: "night" },
+                    theme: "B",
+                },
+            ]);
+            const unreachable = sameTime.issues.filter((i) => i.type === "unreachable");
+            assert.strictEqual(unreachable.length, 1);
+            assert.strictEqual(unreachable[0].ruleIndex, 1);
+        });
+
+        test("considers schedules in shadowing detection", async () => {
+            const rules: ThemeRule[] = [
+                { name: "Work", when: { language: "go", schedule: "* 9-17 * * 1-5" }, theme: "A" },
+                {
+                    name: "Work Tests",
+                    when: { language: "go", pattern: "**/*_test.go", schedule: " * 9-17 * * 1-5 " },
+                    theme: "B",
+                },
+                {
+                    name: "Weekend Tests",
+                    when: { language: "go", pattern: "**/*_test.go", schedule: "* * * * 0,6" },
+                    theme: "C",
+                },
+            ];
+
+            const result = await lintRules(rules);
+            const unreachable = result.issues.filter((i) => i.type === "unreachable");
+
+            // surrounding whitespace doesn't change the schedule; a different schedule does
+            assert.deepStrictEqual(unreachable.map((issue) => issue.ruleIndex), [1]);
+        });
+
+        test("considers custom conditions in shadowing detection", async () => {
+            const shadow: ThemeRule = {
+                name: "CI passing",
+                when: { language: "typescript", custom: { ci: "passing" } },
+                theme: "Green",
+            };
+            const cases: Array<{ target: ThemeRule["when"]; shadowed: boolean }> = [
+                // same provider & value, narrower file condition
+                {
+                    target: {
+                        language: "typescript",
+                        pattern: "**/*.ts",
+                        custom: { ci: "passing" },
+                    },
+                    shadowed: true,
+                },
+                // same provider, different value: never both match
+                { target: { language: "typescript", custom: { ci: "failing" } }, shadowed: false },
+                // disjoint providers overlap but the target doesn't require ci=passing
+                { target: { language: "typescript", custom: { focus: "on" } }, shadowed: false },
+                // extra custom keys make the target more specific
+                {
+                    target: { language: "typescript", custom: { ci: "passing", focus: "on" } },
+                    shadowed: false,
+                },
+            ];
+
+            for (const { target, shadowed } of cases) {
+                const targetRule: ThemeRule = { name: "Target", when: target, theme: "B" };
+                const result = await lintRules([shadow, targetRule]);
+                const unreachable = result.issues.filter((i) => i.type === "unreachable");
+                assert.strictEqual(unreachable.length, shadowed ? 1 : 0, JSON.stringify(target));
+            }
+        });
     });
 
     suite("Invalid Pattern Detection", () => {
@@ -561,4 +643,25 @@ suite("Rule Linter", () => {
             assert.strictEqual(unreachable?.suggestedFix.action, "reorder");
         });
     });
+
+    suite("Malformed Settings", () => {
+        test("skips malformed rules from settings without throwing", async () => {
+            const malformed = createMalformedRules();
+            const rules: ThemeRule[] = [
+                ...malformed,
+                { name: "Original", when: { language: "typescript" }, theme: "Default Dark+" },
+                { name: "Duplicate", when: { language: "typescript" }, theme: "Default Dark+" },
+            ];
+
+            const result = await lintRules(rules);
+
+            assert.ok(result.issues.every((issue) => issue.ruleIndex >= malformed.length));
+            assert.ok(
+                result.issues.some(
+                    (issue) =>
+                        issue.type === "duplicate" && issue.ruleIndex === malformed.length + 1
+                )
+            );
+        });
+    });
 });
@@ -2,8 +2,9 @@
 // Tests for rule operations (delete, move, reorder)
 
 import * as assert from "assert";
+import * as vscode from "vscode";
 import { deleteRules, moveRule, reorderRules } from "../utils/ruleOperations";
-import { loadConfig, updateAllRules } from "../config";
+import { getActiveRuleSet, loadConfig, refreshConfig, updateAllRules } from "../config";
 import { ThemeRule } from "../types";
 
 suite("Rule Operations", () => {
@@ -100,6 +101,29 @@ suite("Rule Operations", () => {
             const config = loadConfig();
             assert.strictEqual(config.rules.length, 0);
         });
+
+        test("deletes from the active profile's own rules", async () => {
+            const settings = vscode.workspace.getConfiguration("reactiveThemes");
+            const target = vscode.ConfigurationTarget.Global;
+            await updateAllRules([createTestRule("Top 0")]);
+            const profileRules = [createTestRule("Work 0"), createTestRule("Work 1")];
+            await settings.update("profiles", { work: { rules: profileRules } }, target);
+            await settings.update("activeProfile", "work", target);
+            refreshConfig();
+
+            try {
+                await deleteRules([0]);
+
+                const config = loadConfig();
+                const names = (rules: ThemeRule[]) => rules.map((rule) => rule.name);
+                assert.deepStrictEqual(names(getActiveRuleSet(config).rules), ["Work 1"]);
+                assert.deepStrictEqual(names(config.rules), ["Top 0"]);
+            } finally {
+                await settings.update("activeProfile", undefined, target);
+                await settings.update("profiles", undefined, target);
+                refreshConfig();
+            }
+        });
     });
 
     suite("moveRule", () => {
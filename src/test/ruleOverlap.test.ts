@@ -7,6 +7,7 @@ import {
     rulesOverlap,
 } from "../ruleOverlap";
 import { ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
 
 suite("Rule Overlap Detection", () => {
     const rules: ThemeRule[] = [
@@ -243,4 +244,81 @@ suite("Rule Overlap Detection", () => {
             assert.ok(rulesOverlap(ruleA, ruleB));
         });
     });
+
+    suite("Context Condition Overlap", () => {
+        const rule = (when: ThemeRule["when"]): ThemeRule => ({ name: "Rule", when, theme: "A" });
+
+        test("custom conditions on the same provider must agree", () => {
+            const passing = rule({ language: "typescript", custom: { ci: "passing" } });
+            const failing = rule({ language: "typescript", custom: { ci: "failing" } });
+            const alsoPassing = rule({ language: "typescript", custom: { ci: "passing" } });
+
+            assert.ok(!rulesOverlap(passing, failing));
+            assert.ok(rulesOverlap(passing, alsoPassing));
+        });
+
+        test("custom conditions on disjoint providers overlap", () => {
+            const ci = rule({ language: "typescript", custom: { ci: "passing" } });
+            const focus = rule({ language: "typescript", custom: { focus: "on" } });
+            const plain = rule({ language: "typescript" });
+
+            assert.ok(rulesOverlap(ci, focus));
+            assert.ok(rulesOverlap(ci, plain));
+        });
+
+        test("custom condition key ignores property order", () => {
+            const a = rule({ custom: { ci: "passing", focus: "on" } });
+            const b = rule({ custom: { focus: "on", ci: "passing" } });
+            const c = rule({ custom: { ci: "passing" } });
+
+            assert.strictEqual(getRuleConditionKey(a), getRuleConditionKey(b));
+            assert.ok(rulesHaveIdenticalConditions(a, b));
+            assert.ok(!rulesHaveIdenticalConditions(a, c));
+        });
+
+        test("time of day conflicts prevent overlap", () => {
+            const day = rule({ language: "markdown", timeOfDay: "day" });
+            const night = rule({ language: "markdown", timeOfDay: "night" });
+            const any = rule({ language: "markdown" });
+
+            assert.ok(!rulesOverlap(day, night));
+            assert.ok(rulesOverlap(day, any));
+        });
+
+        test("schedules compare after trimming", () => {
+            const work = rule({ language: "go", schedule: "* 9-17 * * 1-5" });
+            const padded = rule({ language: "go", schedule: " * 9-17 * * 1-5 " });
+            const weekend = rule({ language: "go", schedule: "* * * * 0,6" });
+
+            assert.ok(rulesOverlap(work, padded));
+            assert.ok(rulesHaveIdenticalConditions(work, padded));
+            assert.ok(!rulesOverlap(work, weekend));
+        });
+
+        test("different schedules that share a minute overlap", () => {
+            const work = rule({ language: "go", schedule: "* 9-17 * * 1-5" });
+            const lunch = rule({ language: "go", schedule: "* 12-13 * * *" });
+            const night = rule({ language: "go", schedule: "* 0-6 * * *" });
+
+            assert.ok(rulesOverlap(work, lunch));
+            assert.ok(!rulesOverlap(work, night));
+        });
+    });
+
+    test("skips malformed rules from settings without throwing", () => {
+        const valid: ThemeRule[] = [
+            { name: "TS", when: { language: "typescript" }, theme: "Dark" },
+            { name: "TS Tests", when: { pattern: "**/*.test.ts" }, theme: "Light" },
+        ];
+        const malformed = createMalformedRules();
+        const all = [...malformed, ...valid];
+
+        const overlapMap = buildOverlapMap(all);
+        assert.deepStrictEqual(
+            Array.from(overlapMap.keys()).sort(),
+            [malformed.length, malformed.length + 1]
+        );
+        assert.deepStrictEqual(findOverlappingRules(valid[0], all), [valid[1]]);
+        all.forEach((entry) => getRuleConditionKey(entry));
+    });
 });
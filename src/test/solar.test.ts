@@ -0,0 +1,72 @@
+// src/test/solar.test.ts
+// Tests for sunrise/sunset calculations
+
+import * as assert from "assert";
+import {
+    getNextSolarTransition,
+    getSunTimes,
+    getTimeOfDay,
+    validateLocation,
+} from "../utils/solar";
+
+const NEW_YORK = { latitude: 40.7128, longitude: -74.006 };
+const SYDNEY = { latitude: -33.8688, longitude: 151.2093 };
+const TROMSO = { latitude: 69.6492, longitude: 18.9553 };
+
+const TOLERANCE_MS = 5 * 60 * 1000;
+
+function assertNear(actual: Date | undefined, expectedIso: string): void {
+    assert.ok(actual, `expected a time near ${expectedIso}`);
+    const delta = Math.abs(actual!.getTime() - new Date(expectedIso).getTime());
+    assert.ok(delta <= TOLERANCE_MS, `${actual!.toISOString()} is not near ${expectedIso}`);
+}
+
+suite("Solar", () => {
+    test("computes summer solstice sunrise & sunset for New York", () => {
+        const times = getSunTimes(new Date("2024-06-21T16:00:00Z"), NEW_YORK);
+        assertNear(times.sunrise, "2024-06-21T09:25:00Z");
+        assertNear(times.sunset, "2024-06-22T00:31:00Z");
+        assert.strictEqual(times.polar, undefined);
+    });
+
+    test("handles southern hemisphere & date line offsets", () => {
+        const times = getSunTimes(new Date("2024-01-01T02:00:00Z"), SYDNEY);
+        assertNear(times.sunrise, "2023-12-31T18:47:00Z");
+        assertNear(times.sunset, "2024-01-01T09:09:00Z");
+    });
+
+    test("reports polar day & polar night", () => {
+        assert.strictEqual(getSunTimes(new Date("2024-06-21T12:00:00Z"), TROMSO).polar, "day");
+        assert.strictEqual(getSunTimes(new Date("2024-12-21T12:00:00Z"), TROMSO).polar, "night");
+        assert.strictEqual(getTimeOfDay(new Date("2024-12-21T12:00:00Z"), TROMSO), "night");
+        assert.strictEqual(
+            getNextSolarTransition(new Date("2024-06-21T12:00:00Z"), TROMSO),
+            undefined
+        );
+    });
+
+    test("determines day/night phase around midnight UTC", () => {
+        // 23:00 EDT is 03:00 UTC the next day
+        assert.strictEqual(getTimeOfDay(new Date("2024-06-21T03:00:00Z"), NEW_YORK), "night");
+        assert.strictEqual(getTimeOfDay(new Date("2024-06-21T16:00:00Z"), NEW_YORK), "day");
+        // 19:00 EDT, still before sunset
+        assert.strictEqual(getTimeOfDay(new Date("2024-06-21T23:00:00Z"), NEW_YORK), "day");
+    });
+
+    test("finds the next transition", () => {
+        const beforeSunrise = getNextSolarTransition(new Date("2024-06-21T03:00:00Z"), NEW_YORK);
+        assert.strictEqual(beforeSunrise?.phase, "day");
+        assertNear(beforeSunrise?.at, "2024-06-21T09:25:00Z");
+
+        const afternoon = getNextSolarTransition(new Date("2024-06-21T23:00:00Z"), NEW_YORK);
+        assert.strictEqual(afternoon?.phase, "night");
+        assertNear(afternoon?.at, "2024-06-22T00:31:00Z");
+    });
+
+    test("validates location ranges", () => {
+        assert.strictEqual(validateLocation(NEW_YORK), undefined);
+        assert.ok(validateLocation({ latitude: 91, longitude: 0 })?.includes("Latitude"));
+        assert.ok(validateLocation({ latitude: 0, longitude: -181 })?.includes("Longitude"));
+        assert.ok(validateLocation(undefined));
+    });
+});
@@ -0,0 +1,50 @@
+// src/test/testRule.test.ts
+// Tests for Test Rule command helpers
+
+import * as assert from "assert";
+import {
+    collectCustomProviderIds,
+    evaluateAllRules,
+    findActiveSchedules,
+    ruleHasContextCondition,
+} from "../commands/testRule";
+import { ThemeRule } from "../types";
+import { createMalformedRules } from "./testUtils";
+
+suite("Test Rule", () => {
+    const workday: ThemeRule = {
+        name: "Workday",
+        when: { schedule: "* 9-17 * * 1-5", custom: { ci: "failed" } },
+        theme: "Focus",
+    };
+    const markdown: ThemeRule = { name: "md", when: { language: "markdown" }, theme: "Docs" };
+    const context = { languageId: "markdown", filePath: "/repo/README.md" };
+
+    test("detects context conditions & skips malformed entries", () => {
+        assert.strictEqual(ruleHasContextCondition(workday), true);
+        assert.strictEqual(ruleHasContextCondition(markdown), false);
+        for (const rule of createMalformedRules()) {
+            assert.strictEqual(ruleHasContextCondition(rule), false);
+        }
+    });
+
+    test("collects schedules & custom ids from well-formed rules only", () => {
+        const rules = [...createMalformedRules(), workday];
+        // Monday 10:30 local time
+        const monday = new Date(2025, 0, 6, 10, 30);
+
+        assert.deepStrictEqual(findActiveSchedules(rules, monday), ["* 9-17 * * 1-5"]);
+        assert.deepStrictEqual(findActiveSchedules(rules, new Date(2025, 0, 5, 10, 30)), []);
+        assert.deepStrictEqual(collectCustomProviderIds(rules), ["ci"]);
+    });
+
+    test("evaluates well-formed rules & keeps their settings index", () => {
+        const rules = [...createMalformedRules(), markdown];
+
+        const results = evaluateAllRules(rules, context);
+        assert.strictEqual(results.length, 1);
+        assert.strictEqual(results[0].rule, markdown);
+        assert.strictEqual(results[0].index, rules.length - 1);
+        assert.strictEqual(results[0].matched, true);
+    });
+});
@@ -4,6 +4,8 @@
 import * as vscode from "vscode";
 import { ThemeRule } from "../types";
 import { Context } from "../contextManager";
+import { Clock } from "../utils/clock";
+import { ThemeManager, ThemeManagerOptions } from "../themeManager";
 
 // * create mock editor w/ specified language & path
 export function createMockEditor(languageId: string, filePath: string): vscode.TextEditor {
@@ -96,3 +98,99 @@ export function createMockRuleSet(): ThemeRule[] {
         }),
     ];
 }
+
+// * create settings entries that don't match the declared rule shape
+export function createMalformedRules(): ThemeRule[] {
+    const junk: unknown[] = [null, 0, "rule", [], true, { when: [] }, { when: null }];
+    const malformedWhen: unknown[] = [
+        { language: 5 },
+        { pattern: { glob: "*" } },
+        { schedule: ["* * * * *"] },
+        { custom: "ci=ok" },
+        { timerInterval: "5" },
+        { debugSession: 1, timeOfDay: {} },
+        { language: 5, pattern: "**/*.ts" },
+        { language: "typescript", pattern: 7 },
+        { custom: null, schedule: 9 },
+    ];
+    return [
+        ...junk,
+        ...malformedWhen.map((when) => ({ name: "Bad", when, theme: "A" })),
+    ] as ThemeRule[];
+}
+
+// * seeded pseudo-random ints in [0, max) so generated test inputs are reproducible
+export function createSeededRandom(seed: number): (max: number) => number {
+    let state = seed >>> 0;
+    return (max) => {
+        // 32-bit LCG (Numerical Recipes constants)
+        state = (Math.imul(state, 1664525) + 1013904223) >>> 0;
+        return state % max;
+    };
+}
+
+// * create Map-backed memento (stands in for globalState; share one to simulate windows)
+export function createMockMemento(initial: Record<string, unknown> = {}): vscode.Memento {
+    const stored = new Map<string, unknown>(Object.entries(initial));
+    return {
+        keys: () => Array.from(stored.keys()),
+        get: <T>(key: string, defaultValue?: T) =>
+            (stored.has(key) ? stored.get(key) : defaultValue) as T,
+        update: async (key: string, value: unknown) => {
+            stored.set(key, value);
+        },
+    };
+}
+
+// * create theme manager w/ no debounce that never touches workbench settings
+export function createTestThemeManager(options: ThemeManagerOptions = {}): ThemeManager {
+    return new ThemeManager({
+        debounceMs: 0,
+        setTheme: async () => {},
+        readCurrentTheme: () => "original-theme",
+        ...options,
+    });
+}
+
+// fake clock that only moves when advanced; timers fire in due order
+export interface FakeClock extends Clock {
+    advance(ms: number): void;
+    pendingTimers(): number;
+}
+
+// * create fake clock starting at given time
+export function createFakeClock(start: Date): FakeClock {
+    let now = start.getTime();
+    let nextId = 1;
+    const timers = new Map<number, { at: number; callback: () => void }>();
+
+    return {
+        now: () => new Date(now),
+        setTimeout: (callback, delayMs) => {
+            const id = nextId++;
+            timers.set(id, { at: now + Math.max(0, delayMs), callback });
+            return id as unknown as NodeJS.Timeout;
+        },
+        clearTimeout: (handle) => {
+            timers.delete(handle as unknown as number);
+        },
+        advance: (ms) => {
+            const target = now + ms;
+            for (;;) {
+                // fire earliest due timer; callbacks may schedule new timers
+                const due = Array.from(timers.entries())
+                    .filter(([, timer]) => timer.at <= target)
+                    .sort(([, a], [, b]) => a.at - b.at)[0];
+                if (!due) {
+                    break;
+                }
+                const [id, timer] = due;
+                timers.delete(id);
+                now = timer.at;
+                timer.callback();
+            }
+            now = target;
+        },
+        pendingTimers: () => timers.size,
+    };
+}
@@ -1,16 +1,15 @@
 import * as assert from "assert";
-import { ThemeManager } from "../themeManager";
+import { MAX_THEME_HISTORY, ThemeApplyEvent } from "../themeManager";
+import { createMockMemento, createTestThemeManager } from "./testUtils";
 
 suite("ThemeManager", () => {
     test("restores original theme when disabled", async () => {
         const appliedThemes: string[] = [];
-        const manager = new ThemeManager(
-            0,
-            async (theme) => {
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
                 appliedThemes.push(theme);
             },
-            () => "original-theme"
-        );
+        });
 
         (manager as any).currentAppliedTheme = "other-theme";
 
@@ -20,13 +19,11 @@ suite("ThemeManager", () => {
 
     test("ignores queued theme changes while disabled", async () => {
         const appliedThemes: string[] = [];
-        const manager = new ThemeManager(
-            0,
-            async (theme) => {
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
                 appliedThemes.push(theme);
             },
-            () => "original-theme"
-        );
+        });
 
         await manager.setEnabled(false);
         appliedThemes.length = 0; // reset counts after restore
@@ -36,4 +33,305 @@ suite("ThemeManager", () => {
 
         assert.strictEqual(appliedThemes.length, 0);
     });
+
+    test("recovers original theme persisted by a session that didn't restore it", async () => {
+        const state = createMockMemento();
+
+        let currentTheme = "user-theme";
+        const setTheme = async (theme: string) => {
+            currentTheme = theme;
+        };
+
+        const firstSession = createTestThemeManager({
+            setTheme,
+            readCurrentTheme: () => currentTheme,
+            state,
+        });
+        assert.strictEqual(firstSession.getOriginalTheme(), "user-theme");
+        await (firstSession as any).applyThemeImmediate("rule-theme");
+
+        // window crashed while the rule theme was active
+        const secondSession = createTestThemeManager({
+            setTheme,
+            readCurrentTheme: () => currentTheme,
+            state,
+        });
+        assert.strictEqual(secondSession.getOriginalTheme(), "user-theme");
+
+        // user picked a theme themselves between sessions
+        currentTheme = "picked-theme";
+        const thirdSession = createTestThemeManager({
+            setTheme,
+            readCurrentTheme: () => currentTheme,
+            state,
+        });
+        assert.strictEqual(thirdSession.getOriginalTheme(), "picked-theme");
+    });
+
+    test("persists an original theme set through the API", async () => {
+        const state = createMockMemento();
+
+        let currentTheme = "user-theme";
+        const setTheme = async (theme: string) => {
+            currentTheme = theme;
+        };
+
+        const firstSession = createTestThemeManager({
+            setTheme,
+            readCurrentTheme: () => currentTheme,
+            state,
+        });
+        await firstSession.setOriginalTheme(" preferred-theme ");
+        assert.strictEqual(firstSession.getOriginalTheme(), "preferred-theme");
+        await assert.rejects(firstSession.setOriginalTheme("  "), /cannot be empty/);
+
+        await (firstSession as any).applyThemeImmediate("rule-theme");
+        const secondSession = createTestThemeManager({
+            setTheme,
+            readCurrentTheme: () => currentTheme,
+            state,
+        });
+        assert.strictEqual(secondSession.getOriginalTheme(), "preferred-theme");
+    });
+
+    test("reports apply outcomes to observers & stats", async () => {
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
+                if (theme === "missing-theme") {
+                    throw new Error("not installed");
+                }
+            },
+        });
+
+        const events: ThemeApplyEvent[] = [];
+        const subscription = manager.onDidApplyTheme((event) => events.push(event));
+
+        await (manager as any).applyThemeImmediate("dark-theme", "matched rule");
+        await (manager as any).applyThemeImmediate("missing-theme", "fallback");
+
+        assert.strictEqual(events.length, 2);
+        assert.strictEqual(events[0].theme, "dark-theme");
+        assert.strictEqual(events[0].reason, "matched rule");
+        assert.strictEqual(events[0].succeeded, true);
+        assert.ok(events[0].durationMs >= 0);
+        assert.strictEqual(events[1].previousTheme, "dark-theme");
+        assert.strictEqual(events[1].succeeded, false);
+        assert.strictEqual(events[1].error, "not installed");
+
+        const stats = manager.getApplyStats();
+        assert.strictEqual(stats.applied, 1);
+        assert.strictEqual(stats.failed, 1);
+
+        subscription.dispose();
+        manager.dispose();
+    });
+
+    test("apply duration excludes the globalState write", async () => {
+        const state = {
+            ...createMockMemento(),
+            update: () => new Promise<void>((resolve) => setTimeout(resolve, 50)),
+        };
+        const manager = createTestThemeManager({ state });
+
+        await (manager as any).applyThemeImmediate("rule-theme", "rule A");
+        assert.ok(manager.getHistory()[0].durationMs < 50);
+        manager.dispose();
+    });
+
+    test("keeps a bounded history newest first", async () => {
+        const manager = createTestThemeManager();
+
+        for (let i = 0; i < MAX_THEME_HISTORY + 5; i++) {
+            await (manager as any).applyThemeImmediate(`theme-${i}`, `rule ${i}`);
+        }
+
+        const history = manager.getHistory();
+        assert.strictEqual(history.length, MAX_THEME_HISTORY);
+        assert.strictEqual(history[0].theme, `theme-${MAX_THEME_HISTORY + 4}`);
+        assert.strictEqual(history[0].reason, `rule ${MAX_THEME_HISTORY + 4}`);
+        assert.strictEqual(history[history.length - 1].theme, "theme-5");
+
+        manager.dispose();
+    });
+
+    test("reverts the last change & suppresses the reverted theme", async () => {
+        const appliedThemes: string[] = [];
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
+                appliedThemes.push(theme);
+            },
+        });
+
+        await (manager as any).applyThemeImmediate("light-theme", "rule A");
+        await (manager as any).applyThemeImmediate("bad-theme", "rule B");
+
+        assert.strictEqual(await manager.revertToPreviousTheme(), "light-theme");
+        assert.strictEqual(manager.getCurrentAppliedTheme(), "light-theme");
+        assert.strictEqual(manager.getRevertedTheme(), "bad-theme");
+
+        // the same automatic decision doesn't bring the reverted theme back
+        manager.applyTheme("bad-theme", "rule B");
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        assert.strictEqual(manager.getCurrentAppliedTheme(), "light-theme");
+
+        // a different theme clears the suppression
+        manager.applyTheme("other-theme", "rule C");
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        assert.strictEqual(manager.getCurrentAppliedTheme(), "other-theme");
+        assert.strictEqual(manager.getRevertedTheme(), undefined);
+
+        assert.deepStrictEqual(appliedThemes, [
+            "light-theme",
+            "bad-theme",
+            "light-theme",
+            "other-theme",
+        ]);
+        manager.dispose();
+    });
+
+    test("revert falls back to the original theme & no-ops without changes", async () => {
+        const manager = createTestThemeManager();
+        assert.strictEqual(await manager.revertToPreviousTheme(), undefined);
+
+        await (manager as any).applyThemeImmediate("rule-theme", "rule A");
+        assert.strictEqual(await manager.revertToPreviousTheme(), "original-theme");
+
+        manager.dispose();
+    });
+
+    test("a second revert doesn't bring the reverted theme back", async () => {
+        const appliedThemes: string[] = [];
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
+                appliedThemes.push(theme);
+            },
+        });
+
+        await (manager as any).applyThemeImmediate("light-theme", "rule A");
+        await (manager as any).applyThemeImmediate("bad-theme", "rule B");
+
+        assert.strictEqual(await manager.revertToPreviousTheme(), "light-theme");
+        assert.strictEqual(await manager.revertToPreviousTheme(), undefined);
+        assert.strictEqual(manager.getCurrentAppliedTheme(), "light-theme");
+        assert.strictEqual(manager.getRevertedTheme(), "bad-theme");
+        assert.deepStrictEqual(appliedThemes, ["light-theme", "bad-theme", "light-theme"]);
+        manager.dispose();
+    });
+
+    test("pauses switching after a manual theme change until resumed", async () => {
+        const appliedThemes: string[] = [];
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
+                appliedThemes.push(theme);
+            },
+        });
+
+        await (manager as any).applyThemeImmediate("rule-theme", "rule A");
+
+        // our own write isn't treated as a manual change
+        assert.strictEqual(manager.noteExternalThemeChange("rule-theme"), false);
+        assert.strictEqual(manager.getManualOverride(), undefined);
+
+        assert.strictEqual(manager.noteExternalThemeChange("picked-theme"), true);
+        assert.strictEqual(manager.getManualOverride(), "picked-theme");
+        assert.strictEqual(manager.getHistory()[0].reason, "manual theme change");
+
+        manager.applyTheme("other-theme", "rule B");
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        await manager.restoreOriginalTheme();
+        assert.deepStrictEqual(appliedThemes, ["rule-theme"]);
+
+        assert.strictEqual(manager.resumeAutomaticSwitching(), true);
+        assert.strictEqual(manager.resumeAutomaticSwitching(), false);
+
+        manager.applyTheme("other-theme", "rule B");
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        assert.deepStrictEqual(appliedThemes, ["rule-theme", "other-theme"]);
+        manager.dispose();
+    });
+
+    test("ignores theme changes written by another window", async () => {
+        const state = createMockMemento();
+
+        // two windows share globalState & the user-level workbench.colorTheme setting
+        const windowA = createTestThemeManager({ state });
+        const windowB = createTestThemeManager({ state });
+
+        await (windowA as any).applyThemeImmediate("rule-theme", "rule A");
+        assert.strictEqual(windowB.noteExternalThemeChange("rule-theme"), false);
+        assert.strictEqual(windowB.getManualOverride(), undefined);
+
+        // a theme neither window applied is still a manual pick
+        assert.strictEqual(windowB.noteExternalThemeChange("picked-theme"), true);
+
+        windowA.dispose();
+        windowB.dispose();
+    });
+
+    test("records the applied theme before other windows see the setting change", async () => {
+        const state = createMockMemento();
+        let seenAsManual: boolean | undefined;
+
+        // the settings change reaches other windows while setTheme is still in flight
+        const windowB = createTestThemeManager({ state });
+        const windowA = createTestThemeManager({
+            state,
+            setTheme: async (theme) => {
+                seenAsManual = windowB.noteExternalThemeChange(theme);
+            },
+        });
+
+        await (windowA as any).applyThemeImmediate("rule-theme", "rule A");
+        assert.strictEqual(seenAsManual, false);
+        assert.strictEqual(windowB.getManualOverride(), undefined);
+
+        windowA.dispose();
+        windowB.dispose();
+    });
+
+    test("rolls back the recorded theme when applying fails", async () => {
+        const state = createMockMemento({ "reactiveThemes.lastAppliedTheme": "rule-theme" });
+        const manager = createTestThemeManager({
+            state,
+            setTheme: async () => {
+                throw new Error("theme not installed");
+            },
+        });
+
+        await (manager as any).applyThemeImmediate("missing-theme", "rule B");
+        assert.strictEqual(state.get("reactiveThemes.lastAppliedTheme"), "rule-theme");
+        assert.strictEqual(manager.getApplyStats().failed, 1);
+        manager.dispose();
+    });
+
+    test("sources that outrank manual picks still apply", async () => {
+        const appliedThemes: string[] = [];
+        const manager = createTestThemeManager({
+            setTheme: async (theme) => {
+                appliedThemes.push(theme);
+            },
+        });
+        manager.noteExternalThemeChange("picked-theme");
+
+        manager.applyTheme("rule-theme", "rule A");
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        assert.deepStrictEqual(appliedThemes, []);
+
+        manager.applyTheme("rule-theme", "rule A", { overrideManual: true });
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        assert.deepStrictEqual(appliedThemes, ["rule-theme"]);
+        assert.strictEqual(manager.getManualOverride(), "picked-theme");
+        manager.dispose();
+    });
+
+    test("reverting a manual change resumes switching", async () => {
+        const manager = createTestThemeManager();
+        await (manager as any).applyThemeImmediate("rule-theme", "rule A");
+        manager.noteExternalThemeChange("picked-theme");
+
+        assert.strictEqual(await manager.revertToPreviousTheme(), "rule-theme");
+        assert.strictEqual(manager.getManualOverride(), undefined);
+        assert.strictEqual(manager.getRevertedTheme(), "picked-theme");
+        manager.dispose();
+    });
 });
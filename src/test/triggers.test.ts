@@ -5,7 +5,10 @@ import { DebugTrigger } from "../triggers/debugTrigger";
 import { ViewTrigger } from "../triggers/viewTrigger";
 import { TimerTrigger } from "../triggers/timerTrigger";
 import { TestTrigger } from "../triggers/testTrigger";
+import { ScheduleTrigger } from "../triggers/scheduleTrigger";
+import { SolarTrigger } from "../triggers/solarTrigger";
 import { ThemeRule } from "../types";
+import { createFakeClock } from "./testUtils";
 
 suite("Triggers", () => {
     test("DebugTrigger updates context on start/stop", () => {
@@ -71,38 +74,31 @@ suite("Triggers", () => {
         trigger.dispose();
     });
 
-    test("TimerTrigger dispatches pending ticks", () => {
-        const originalSetTimeout = global.setTimeout;
-        const originalSetInterval = global.setInterval;
-        const timeouts: Function[] = [];
-
-        (global as any).setTimeout = (fn: any) => {
-            timeouts.push(fn);
-            return 0 as any;
-        };
-        (global as any).setInterval = () => 0 as any;
-
+    test("TimerTrigger dispatches ticks on an injected clock", () => {
+        const clock = createFakeClock(new Date(2025, 0, 6, 9, 0));
         const manager = new ContextManager();
-        let lastFired: number[] | undefined;
+        const fired: number[][] = [];
         const rules: ThemeRule[] = [
-            { name: "Timer", when: { timerInterval: 1 }, theme: "Theme" },
+            { name: "Every minute", when: { timerInterval: 1 }, theme: "Theme" },
+            { name: "Every two", when: { timerInterval: 2 }, theme: "Other" },
         ];
-        const trigger = new TimerTrigger(manager, (indices) => {
-            lastFired = indices;
-        });
+        const trigger = new TimerTrigger(
+            manager,
+            (indices) => {
+                fired.push([...indices].sort());
+            },
+            clock
+        );
 
         trigger.registerTimerRules(rules);
-        (trigger as any).pendingTicks.add(0);
-        manager.incrementTimerTick();
-        (trigger as any).scheduleDispatch();
-
-        timeouts.forEach((fn) => fn());
-
-        assert.deepStrictEqual(lastFired, [0]);
+        clock.advance(60 * 1000);
+        clock.advance(60 * 1000);
+        assert.deepStrictEqual(fired, [[0], [0, 1]]);
+        assert.strictEqual(manager.getContext().timerTick, 3);
+        assert.deepStrictEqual([...trigger.getLastFiredRuleIndices()].sort(), [0, 1]);
 
         trigger.dispose();
-        (global as any).setTimeout = originalSetTimeout;
-        (global as any).setInterval = originalSetInterval;
+        assert.strictEqual(clock.pendingTimers(), 0);
     });
 
     test("TestTrigger tracks task and testing API runs", () => {
@@ -154,4 +150,53 @@ suite("Triggers", () => {
         trigger.dispose();
         (global as any).setTimeout = originalSetTimeout;
     });
+
+    test("ScheduleTrigger follows cron boundaries on an injected clock", () => {
+        // Monday 08:59:30 local time
+        const clock = createFakeClock(new Date(2025, 0, 6, 8, 59, 30));
+        const manager = new ContextManager();
+        const rules: ThemeRule[] = [
+            { name: "Work hours", when: { schedule: "* 9-16 * * 1-5" }, theme: "WorkTheme" },
+            { name: "Broken", when: { schedule: "not a cron" }, theme: "Ignored" },
+        ];
+
+        const trigger = new ScheduleTrigger(manager, clock);
+        trigger.registerScheduleRules(rules);
+        assert.deepStrictEqual(manager.getContext().activeSchedules ?? [], []);
+
+        clock.advance(31 * 1000);
+        assert.deepStrictEqual(manager.getContext().activeSchedules, ["* 9-16 * * 1-5"]);
+
+        // 17:00 ends the working-hours window
+        clock.advance(8 * 60 * 60 * 1000);
+        assert.deepStrictEqual(manager.getContext().activeSchedules, []);
+
+        trigger.dispose();
+        assert.strictEqual(clock.pendingTimers(), 0);
+    });
+
+    test("SolarTrigger flips phase at sunrise & sunset on an injected clock", () => {
+        const newYork = { latitude: 40.7128, longitude: -74.006 };
+        // 23:00 EDT on June 20, before sunrise (~09:25Z) & sunset (~00:31Z next day)
+        const clock = createFakeClock(new Date("2024-06-21T03:00:00Z"));
+        const manager = new ContextManager();
+
+        const trigger = new SolarTrigger(manager, clock);
+        trigger.setLocation(newYork);
+        assert.strictEqual(manager.getContext().timeOfDay, "night");
+        assert.strictEqual(trigger.getNextTransition()?.phase, "day");
+
+        clock.advance(7 * 60 * 60 * 1000);
+        assert.strictEqual(manager.getContext().timeOfDay, "day");
+        assert.strictEqual(trigger.getNextTransition()?.phase, "night");
+
+        clock.advance(15 * 60 * 60 * 1000);
+        assert.strictEqual(manager.getContext().timeOfDay, "night");
+
+        trigger.setLocation(undefined);
+        assert.strictEqual(manager.getContext().timeOfDay, undefined);
+        assert.strictEqual(clock.pendingTimers(), 0);
+
+        trigger.dispose();
+    });
 });
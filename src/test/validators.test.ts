@@ -10,7 +10,10 @@ import {
     validateDebugType,
     validateTimerInterval,
     validateFilePath,
+    parseCustomConditions,
+    validateCustomConditions,
 } from "../utils/validators";
+import { createSeededRandom } from "./testUtils";
 
 suite("Validators", () => {
     suite("validateRuleName", () => {
@@ -214,4 +217,82 @@ suite("Validators", () => {
             assert.strictEqual(validateFilePath("./src/index.ts"), undefined);
         });
     });
+
+    suite("validateCustomConditions", () => {
+        test("rejects empty string", () => {
+            assert.strictEqual(validateCustomConditions(""), "Custom conditions cannot be empty");
+        });
+
+        test("rejects entries without a value", () => {
+            assert.ok(validateCustomConditions("ci.status=failing, focus")?.includes("id=value"));
+            assert.ok(validateCustomConditions("=failing")?.includes("id=value"));
+        });
+
+        test("parses comma separated pairs", () => {
+            assert.strictEqual(validateCustomConditions("ci.status=failing"), undefined);
+            assert.deepStrictEqual(parseCustomConditions(" ci.status = failing, url=a=b "), {
+                "ci.status": "failing",
+                url: "a=b",
+            });
+        });
+
+        test("rejects the __proto__ provider id", () => {
+            assert.strictEqual(
+                validateCustomConditions("__proto__=x"),
+                'Provider id "__proto__" is reserved'
+            );
+        });
+
+        // ? property-style: generated input either fails w/ a plain Error or round-trips
+        test("generated input parses into trimmed pairs or fails descriptively", () => {
+            const random = createSeededRandom(97);
+            const tokens = [
+                "ci",
+                "ci.status",
+                "focus",
+                "on",
+                "=",
+                "=",
+                ",",
+                ", ",
+                " ",
+                "a=b",
+                "__proto__",
+                "constructor",
+            ];
+
+            for (let i = 0; i < 500; i++) {
+                const input = Array.from(
+                    { length: 1 + random(8) },
+                    () => tokens[random(tokens.length)]
+                ).join("");
+
+                let custom: Record<string, string>;
+                try {
+                    custom = parseCustomConditions(input);
+                } catch (error) {
+                    assert.ok(error instanceof Error, `"${input}" threw a non-Error`);
+                    assert.strictEqual(error.constructor, Error, `"${input}": ${error.message}`);
+                    assert.ok(
+                        /^(Expected id=value|Provider id)/.test(error.message),
+                        error.message
+                    );
+                    continue;
+                }
+
+                // every provider id in the input must survive as an own key
+                input.split(",").forEach((entry) => {
+                    const id = entry.slice(0, entry.indexOf("=")).trim();
+                    assert.ok(Object.hasOwn(custom, id), `"${input}" lost "${id}"`);
+                });
+                const entries = Object.entries(custom);
+                entries.forEach(([id, value]) => {
+                    assert.ok(id && id === id.trim() && !/[=,]/.test(id), `"${input}" id "${id}"`);
+                    assert.ok(value && value === value.trim() && !value.includes(","));
+                });
+                const serialized = entries.map(([id, value]) => `${id}=${value}`).join(",");
+                assert.deepStrictEqual(parseCustomConditions(serialized), custom);
+            }
+        });
+    });
 });
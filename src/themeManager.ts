@@ -4,6 +4,40 @@
 import * as vscode from "vscode";
 import { DEFAULT_DEBOUNCE_MS, getCurrentTheme, setCurrentTheme } from "./config";
 
+// globalState keys used to survive sessions that end w/o restoring the original theme
+const ORIGINAL_THEME_KEY = "reactiveThemes.originalTheme";
+const LAST_APPLIED_THEME_KEY = "reactiveThemes.lastAppliedTheme";
+// number of theme changes kept for the history command & API
+export const MAX_THEME_HISTORY = 50;
+
+export type ThemeStateStore = Pick<vscode.Memento, "get" | "update">;
+
+// collaborators are injectable so tests don't touch workbench settings
+export interface ThemeManagerOptions {
+    debounceMs?: number;
+    setTheme?: (theme: string) => Promise<void>; // defaults to updating workbench.colorTheme
+    readCurrentTheme?: () => string;
+    state?: ThemeStateStore; // globalState, to survive sessions that end w/o restoring
+}
+
+// outcome of a single theme application, for logging & observers
+export interface ThemeApplyEvent {
+    theme: string;
+    previousTheme: string | undefined;
+    reason?: string;
+    succeeded: boolean;
+    error?: string;
+    durationMs: number; // time spent updating workbench.colorTheme
+    timestamp: number;
+}
+
+// running totals since activation
+export interface ThemeApplyStats {
+    applied: number;
+    failed: number;
+    lastDurationMs?: number;
+}
+
 // * manages theme application w/ debouncing & state tracking
 export class ThemeManager {
     // timer for debouncing theme changes
@@ -19,21 +53,51 @@ export class ThemeManager {
 
     private readonly setTheme: (theme: string) => Promise<void>;
     private readonly readCurrentTheme: () => string;
+    private readonly state: ThemeStateStore | undefined;
+
+    private stats: ThemeApplyStats = { applied: 0, failed: 0 };
+    // most recent theme changes, oldest first
+    private history: ThemeApplyEvent[] = [];
+    // theme undone via revertToPreviousTheme; suppressed until another theme is chosen
+    private revertedTheme: string | undefined;
+    // history entries produced by reverts, so a second revert doesn't undo the undo
+    private readonly revertEvents = new WeakSet<ThemeApplyEvent>();
+    // theme the user picked themselves; pauses automatic switching until resumed
+    private manualOverride: string | undefined;
+    // theme currently being written, so our own config change isn't mistaken for a manual one
+    private pendingTheme: string | undefined;
+    private readonly onDidApplyThemeEmitter = new vscode.EventEmitter<ThemeApplyEvent>();
+    // fires after every attempted theme change (successful or not)
+    public readonly onDidApplyTheme = this.onDidApplyThemeEmitter.event;
 
     // initialize manager & store original theme
-    constructor(
-        debounceMs: number = DEFAULT_DEBOUNCE_MS,
-        setTheme: (theme: string) => Promise<void> = (theme) => setCurrentTheme(theme),
-        readCurrentTheme: () => string = () => getCurrentTheme()
-    ) {
-        this.debounceMs = debounceMs;
-        this.setTheme = setTheme;
-        this.readCurrentTheme = readCurrentTheme;
-        this.originalTheme = this.readCurrentTheme();
+    constructor(options: ThemeManagerOptions = {}) {
+        this.debounceMs = options.debounceMs ?? DEFAULT_DEBOUNCE_MS;
+        this.setTheme = options.setTheme ?? ((theme) => setCurrentTheme(theme));
+        this.readCurrentTheme = options.readCurrentTheme ?? (() => getCurrentTheme());
+        this.state = options.state;
+        this.originalTheme = this.resolveOriginalTheme();
+    }
+
+    // * pick the user's own theme, even if last session ended on a rule theme
+    private resolveOriginalTheme(): string {
+        const current = this.readCurrentTheme();
+        const persistedOriginal = this.state?.get<string>(ORIGINAL_THEME_KEY);
+        const lastApplied = this.state?.get<string>(LAST_APPLIED_THEME_KEY);
+
+        // ? current theme is still the one we applied (crash, window reload) => not a user choice
+        const original = persistedOriginal && lastApplied === current ? persistedOriginal : current;
+        void this.state?.update(ORIGINAL_THEME_KEY, original);
+        return original;
     }
 
     // apply theme w/ debouncing to prevent rapid changes
-    applyTheme(themeName: string, reason?: string): void {
+    // overrideManual: the caller's source outranks manual picks (reactiveThemes.precedence)
+    applyTheme(
+        themeName: string,
+        reason?: string,
+        options: { overrideManual?: boolean } = {}
+    ): void {
         // clear any existing debounce timer
         if (this.debounceTimer) {
             clearTimeout(this.debounceTimer);
@@ -44,6 +108,16 @@ export class ThemeManager {
             return;
         }
 
+        // manual theme choices win over rules until switching is resumed
+        if (this.manualOverride && !options.overrideManual) {
+            return;
+        }
+
+        // keep a reverted theme from being re-applied by the same automatic decision
+        if (themeName === this.revertedTheme) {
+            return;
+        }
+
         // debounce theme change
         this.debounceTimer = setTimeout(() => {
             this.applyThemeImmediate(themeName, reason);
@@ -57,24 +131,156 @@ export class ThemeManager {
             return;
         }
 
+        const previousTheme = this.currentAppliedTheme;
+        const lastApplied = this.state?.get<string>(LAST_APPLIED_THEME_KEY);
+        let startedAt = Date.now();
+
         try {
+            this.pendingTheme = themeName;
+            // ? record the theme before writing it, so other windows see the change as ours
+            await this.state?.update(LAST_APPLIED_THEME_KEY, themeName);
+            // measure only the workbench.colorTheme update, not the bookkeeping around it
+            startedAt = Date.now();
             await this.setTheme(themeName);
+            const durationMs = Date.now() - startedAt;
             this.currentAppliedTheme = themeName;
+            this.revertedTheme = undefined;
 
-            // log w/ reason for debugging
+            this.stats.applied++;
+            this.stats.lastDurationMs = durationMs;
+
+            // log w/ reason & duration for debugging
             if (reason) {
-                console.log(`[Reactive Themes] Applied theme "${themeName}" (${reason})`);
+                console.log(
+                    `[Reactive Themes] Applied theme "${themeName}" (${reason}) in ${durationMs}ms`
+                );
             }
+            this.fireApplyEvent({
+                theme: themeName,
+                previousTheme,
+                reason,
+                succeeded: true,
+                durationMs,
+            });
         } catch (error) {
+            const durationMs = Date.now() - startedAt;
+            await this.state?.update(LAST_APPLIED_THEME_KEY, lastApplied);
+            this.stats.failed++;
+            this.stats.lastDurationMs = durationMs;
+
             console.error(`[Reactive Themes] Failed to apply theme "${themeName}":`, error);
+            this.fireApplyEvent({
+                theme: themeName,
+                previousTheme,
+                reason,
+                succeeded: false,
+                error: error instanceof Error ? error.message : String(error),
+                durationMs,
+            });
             vscode.window.showErrorMessage(
                 `Reactive Themes: Failed to apply theme "${themeName}". The theme may not be installed.`
             );
+        } finally {
+            this.pendingTheme = undefined;
+        }
+    }
+
+    // * record a workbench.colorTheme change; returns true if it starts a manual override
+    noteExternalThemeChange(theme: string): boolean {
+        // ignore our own writes & repeated notifications for the same choice
+        if (
+            !theme ||
+            theme === this.pendingTheme ||
+            theme === this.currentAppliedTheme ||
+            theme === this.manualOverride
+        ) {
+            return false;
+        }
+
+        // ? workbench.colorTheme is a user setting, so another window's rule switch lands here too
+        if (theme === this.state?.get<string>(LAST_APPLIED_THEME_KEY)) {
+            return false;
+        }
+
+        if (this.debounceTimer) {
+            clearTimeout(this.debounceTimer);
         }
+
+        const startedOverride = this.manualOverride === undefined;
+        this.fireApplyEvent({
+            theme,
+            previousTheme: this.currentAppliedTheme ?? this.manualOverride,
+            reason: "manual theme change",
+            succeeded: true,
+            durationMs: 0,
+        });
+        this.manualOverride = theme;
+        this.currentAppliedTheme = undefined;
+        console.log(`[Reactive Themes] Manual theme change to "${theme}"; switching paused`);
+        return startedOverride;
+    }
+
+    // * clear manual override; returns true if switching was paused
+    resumeAutomaticSwitching(): boolean {
+        const wasPaused = this.manualOverride !== undefined;
+        this.manualOverride = undefined;
+        return wasPaused;
+    }
+
+    // get theme the user picked manually, if switching is paused
+    getManualOverride(): string | undefined {
+        return this.manualOverride;
+    }
+
+    private fireApplyEvent(event: Omit<ThemeApplyEvent, "timestamp">): void {
+        const recorded = { ...event, timestamp: Date.now() };
+        this.history.push(recorded);
+        if (this.history.length > MAX_THEME_HISTORY) {
+            this.history.splice(0, this.history.length - MAX_THEME_HISTORY);
+        }
+        this.onDidApplyThemeEmitter.fire(recorded);
+    }
+
+    // * undo last theme change; returns the restored theme, if any
+    async revertToPreviousTheme(): Promise<string | undefined> {
+        const lastChange = this.history
+            .filter((event) => event.succeeded && !this.revertEvents.has(event))
+            .pop();
+        const themeToRevert = this.currentAppliedTheme ?? this.manualOverride;
+        const previousTheme = lastChange?.previousTheme ?? this.originalTheme;
+
+        if (!themeToRevert || !previousTheme || previousTheme === themeToRevert) {
+            return undefined;
+        }
+
+        if (this.debounceTimer) {
+            clearTimeout(this.debounceTimer);
+        }
+
+        await this.applyThemeImmediate(previousTheme, `reverted "${themeToRevert}"`);
+        if (this.currentAppliedTheme !== previousTheme) {
+            return undefined;
+        }
+        this.revertEvents.add(this.history[this.history.length - 1]);
+
+        // undoing a manual pick hands control back to the rules
+        this.manualOverride = undefined;
+        this.revertedTheme = themeToRevert;
+        return previousTheme;
+    }
+
+    // get theme suppressed by the last revert, if still in effect
+    getRevertedTheme(): string | undefined {
+        return this.revertedTheme;
     }
 
     // restore original theme from extension activation
     async restoreOriginalTheme(): Promise<void> {
+        // ? a manual choice is the user's current preference; leave it in place
+        if (this.manualOverride) {
+            return;
+        }
+
         if (this.originalTheme && this.currentAppliedTheme !== this.originalTheme) {
             await this.applyThemeImmediate(this.originalTheme, "restoring original");
         }
@@ -121,10 +327,31 @@ export class ThemeManager {
         return this.originalTheme;
     }
 
+    // * replace the theme restored when disabled & persist it for later sessions
+    async setOriginalTheme(theme: string): Promise<void> {
+        const trimmed = theme.trim();
+        if (!trimmed) {
+            throw new Error("Original theme cannot be empty");
+        }
+        this.originalTheme = trimmed;
+        await this.state?.update(ORIGINAL_THEME_KEY, trimmed);
+    }
+
+    // get theme application counters since activation
+    getApplyStats(): ThemeApplyStats {
+        return { ...this.stats };
+    }
+
+    // get recent theme changes since activation, newest first
+    getHistory(): ThemeApplyEvent[] {
+        return [...this.history].reverse();
+    }
+
     // cleanup resources & cancel pending timers
     dispose(): void {
         if (this.debounceTimer) {
             clearTimeout(this.debounceTimer);
         }
+        this.onDidApplyThemeEmitter.dispose();
     }
 }
@@ -0,0 +1,65 @@
+// src/triggers/scheduleTrigger.ts
+// Evaluate cron-style schedule rules each minute & update active schedules in context
+
+import * as vscode from "vscode";
+import { ContextManager } from "../contextManager";
+import { ThemeRule } from "../types";
+import { Clock, systemClock } from "../utils/clock";
+import { cronMatches, validateCronExpression } from "../utils/cron";
+
+const MS_PER_MINUTE = 60 * 1000;
+// fire slightly after the minute boundary so the new minute is observed
+const TICK_GRACE_MS = 250;
+
+export class ScheduleTrigger implements vscode.Disposable {
+    private schedules: string[] = [];
+    private tickHandle: NodeJS.Timeout | undefined;
+
+    constructor(private contextManager: ContextManager, private clock: Clock = systemClock) {}
+
+    public registerScheduleRules(rules: ThemeRule[]): void {
+        this.clearTick();
+
+        const unique = new Set<string>();
+        rules.forEach((rule) => {
+            const schedule = rule?.when?.schedule;
+            if (typeof schedule === "string" && !validateCronExpression(schedule)) {
+                unique.add(schedule.trim());
+            }
+        });
+        this.schedules = Array.from(unique.values());
+
+        this.tick();
+    }
+
+    private tick(): void {
+        this.clearTick();
+
+        if (this.schedules.length === 0) {
+            this.contextManager.setActiveSchedules([]);
+            return;
+        }
+
+        // re-read the wall clock every tick instead of accumulating intervals, so
+        // clock adjustments, DST changes, & sleep/resume are picked up on the next minute
+        const now = this.clock.now();
+        this.contextManager.setActiveSchedules(
+            this.schedules.filter((schedule) => cronMatches(schedule, now))
+        );
+
+        const untilNextMinute = MS_PER_MINUTE - (now.getTime() % MS_PER_MINUTE);
+        this.tickHandle = this.clock.setTimeout(() => this.tick(), untilNextMinute + TICK_GRACE_MS);
+    }
+
+    private clearTick(): void {
+        if (this.tickHandle) {
+            this.clock.clearTimeout(this.tickHandle);
+            this.tickHandle = undefined;
+        }
+    }
+
+    public dispose(): void {
+        this.clearTick();
+        this.schedules = [];
+    }
+}
@@ -0,0 +1,71 @@
+// src/triggers/solarTrigger.ts
+// Track sunrise/sunset for the configured location & update time-of-day context
+
+import * as vscode from "vscode";
+import { ContextManager } from "../contextManager";
+import { Clock, systemClock } from "../utils/clock";
+import {
+    GeoLocation,
+    SolarTransition,
+    getNextSolarTransition,
+    getTimeOfDay,
+    validateLocation,
+} from "../utils/solar";
+
+// re-check at least hourly so sleep/resume & clock changes can't leave a stale phase
+const MAX_REFRESH_MS = 60 * 60 * 1000;
+// small delay past the transition so the recomputed phase has flipped
+const TRANSITION_GRACE_MS = 1000;
+
+export class SolarTrigger implements vscode.Disposable {
+    private location: GeoLocation | undefined;
+    private refreshHandle: NodeJS.Timeout | undefined;
+    private nextTransition: SolarTransition | undefined;
+
+    constructor(private contextManager: ContextManager, private clock: Clock = systemClock) {}
+
+    // set or clear observer location; invalid locations disable time-of-day tracking
+    public setLocation(location: GeoLocation | undefined): void {
+        this.location = location && !validateLocation(location) ? location : undefined;
+        this.refresh();
+    }
+
+    // next sunrise/sunset, if a location is configured & the sun rises/sets soon
+    public getNextTransition(): SolarTransition | undefined {
+        return this.nextTransition;
+    }
+
+    private refresh(): void {
+        this.clearRefresh();
+
+        if (!this.location) {
+            this.nextTransition = undefined;
+            this.contextManager.setTimeOfDay(undefined);
+            return;
+        }
+
+        const now = this.clock.now();
+        this.contextManager.setTimeOfDay(getTimeOfDay(now, this.location));
+        this.nextTransition = getNextSolarTransition(now, this.location);
+
+        const untilTransition = this.nextTransition
+            ? this.nextTransition.at.getTime() - now.getTime() + TRANSITION_GRACE_MS
+            : MAX_REFRESH_MS;
+        const delay = Math.max(TRANSITION_GRACE_MS, Math.min(untilTransition, MAX_REFRESH_MS));
+
+        this.refreshHandle = this.clock.setTimeout(() => this.refresh(), delay);
+    }
+
+    private clearRefresh(): void {
+        if (this.refreshHandle) {
+            this.clock.clearTimeout(this.refreshHandle);
+            this.refreshHandle = undefined;
+        }
+    }
+
+    public dispose(): void {
+        this.clearRefresh();
+        this.location = undefined;
+        this.nextTransition = undefined;
+    }
+}
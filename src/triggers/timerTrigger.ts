@@ -4,6 +4,7 @@
 import * as vscode from "vscode";
 import { ContextManager } from "../contextManager";
 import { ThemeRule } from "../types";
+import { Clock, systemClock } from "../utils/clock";
 
 export class TimerTrigger implements vscode.Disposable {
     private timers: Map<string, NodeJS.Timeout> = new Map();
@@ -14,7 +15,8 @@ export class TimerTrigger implements vscode.Disposable {
 
     constructor(
         private contextManager: ContextManager,
-        private onTimerTick: (ruleIndices: number[], rules: ThemeRule[]) => void
+        private onTimerTick: (ruleIndices: number[], rules: ThemeRule[]) => void,
+        private clock: Clock = systemClock
     ) {}
 
     public registerTimerRules(rules: ThemeRule[]): void {
@@ -24,27 +26,31 @@ export class TimerTrigger implements vscode.Disposable {
 
         // Set up new timers for rules with timerInterval
         rules.forEach((rule, index) => {
-            if (rule.when.timerInterval && rule.when.timerInterval > 0) {
-                const intervalMs = rule.when.timerInterval * 60 * 1000;
-                const ruleId = `timer_${index}`;
-
-                const timer = setInterval(() => {
-                    this.contextManager.incrementTimerTick();
-                    this.pendingTicks.add(index);
-                    this.scheduleDispatch();
-                }, intervalMs);
-
-                this.timers.set(ruleId, timer);
+            const interval = rule?.when?.timerInterval;
+            if (typeof interval === "number" && interval > 0) {
+                this.scheduleTick(index, interval * 60 * 1000);
             }
         });
     }
 
+    // Clock has no interval timer, so each tick re-arms the next one
+    private scheduleTick(index: number, intervalMs: number): void {
+        const timer = this.clock.setTimeout(() => {
+            this.contextManager.incrementTimerTick();
+            this.pendingTicks.add(index);
+            this.scheduleDispatch();
+            this.scheduleTick(index, intervalMs);
+        }, intervalMs);
+
+        this.timers.set(`timer_${index}`, timer);
+    }
+
     private scheduleDispatch(): void {
         if (this.dispatchHandle) {
             return;
         }
 
-        this.dispatchHandle = setTimeout(() => {
+        this.dispatchHandle = this.clock.setTimeout(() => {
             this.dispatchHandle = undefined;
             const firedRules = Array.from(this.pendingTicks.values());
             this.pendingTicks.clear();
@@ -61,12 +67,12 @@ export class TimerTrigger implements vscode.Disposable {
     }
 
     private clearTimers(): void {
-        this.timers.forEach((timer) => clearInterval(timer));
+        this.timers.forEach((timer) => this.clock.clearTimeout(timer));
         this.timers.clear();
         this.pendingTicks.clear();
         this.lastFiredRules = [];
         if (this.dispatchHandle) {
-            clearTimeout(this.dispatchHandle);
+            this.clock.clearTimeout(this.dispatchHandle);
             this.dispatchHandle = undefined;
         }
     }
@@ -14,6 +14,9 @@ export interface RuleCondition {
     testState?: "running" | "failed" | "passed" | "none";
     timerInterval?: number; // minutes
     viewMode?: "diff" | "merge" | "normal";
+    timeOfDay?: "day" | "night";
+    schedule?: string; // cron expression, e.g. "* 9-16 * * 1-5"
+    custom?: Record<string, string>; // values published by other extensions' context providers
 }
 
 // single theme rule mapping conditions to a theme
@@ -23,12 +26,27 @@ export interface ThemeRule {
     theme: string;
 }
 
+// named bundle of rules & default theme, switchable at runtime
+export interface ThemeProfile {
+    rules?: ThemeRule[]; // replaces top-level rules when set
+    defaultTheme?: string; // replaces top-level defaultTheme when set
+}
+
+// sources that can decide the theme, ranked by reactiveThemes.precedence
+export type PrecedenceSource = "environment" | "manual" | "rules";
+
 // configuration settings for Reactive Themes extension
 export interface ReactiveThemesConfig {
     enabled: boolean;
     rules: ThemeRule[];
     defaultTheme?: string;
     debounceMs: number;
+    location?: { latitude: number; longitude: number };
+    manualOverride?: "sticky" | "ignore"; // how manual theme picks interact w/ rules
+    precedence?: PrecedenceSource[]; // highest first; default & original theme always come last
+    profiles?: Record<string, ThemeProfile>;
+    activeProfile?: string;
+    validation?: "lenient" | "strict"; // strict rejects configs w/ unknown keys or invalid rules
 }
 
 // result of evaluating rules against current context
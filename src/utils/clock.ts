@@ -0,0 +1,16 @@
+// src/utils/clock.ts
+// Injectable time source for triggers that schedule work against the wall clock
+
+// minimal clock surface used by time-based triggers; tests can swap in a fake
+export interface Clock {
+    now(): Date;
+    setTimeout(callback: () => void, delayMs: number): NodeJS.Timeout;
+    clearTimeout(handle: NodeJS.Timeout): void;
+}
+
+// default clock backed by Date & global timers
+export const systemClock: Clock = {
+    now: () => new Date(),
+    setTimeout: (callback, delayMs) => setTimeout(callback, delayMs),
+    clearTimeout: (handle) => clearTimeout(handle),
+};
@@ -0,0 +1,215 @@
+// src/utils/cron.ts
+// Cron expression parsing & matching for schedule-based rules
+
+// parsed 5-field cron expression (minute hour day-of-month month day-of-week)
+export interface CronSchedule {
+    minutes: Set<number>;
+    hours: Set<number>;
+    daysOfMonth: Set<number>;
+    months: Set<number>;
+    daysOfWeek: Set<number>;
+    // cron semantics: when both day fields are restricted, either may match
+    dayOfMonthRestricted: boolean;
+    dayOfWeekRestricted: boolean;
+}
+
+interface FieldSpec {
+    name: string;
+    min: number;
+    max: number;
+    aliases?: string[];
+}
+
+const MONTH_NAMES = "jan feb mar apr may jun jul aug sep oct nov dec".split(" ");
+const DAY_NAMES = "sun mon tue wed thu fri sat".split(" ");
+
+const FIELDS: FieldSpec[] = [
+    { name: "minute", min: 0, max: 59 },
+    { name: "hour", min: 0, max: 23 },
+    { name: "day of month", min: 1, max: 31 },
+    { name: "month", min: 1, max: 12, aliases: MONTH_NAMES },
+    { name: "day of week", min: 0, max: 7, aliases: DAY_NAMES },
+];
+
+const parsedCache = new Map<string, CronSchedule>();
+
+function parseValue(raw: string, field: FieldSpec): number {
+    const lower = raw.toLowerCase();
+    const aliasIndex = field.aliases?.indexOf(lower) ?? -1;
+    if (aliasIndex >= 0) {
+        // month names are 1-based, day names 0-based
+        return field.name === "month" ? aliasIndex + 1 : aliasIndex;
+    }
+
+    if (!/^\d+$/.test(raw)) {
+        throw new Error(`Invalid ${field.name} value "${raw}"`);
+    }
+
+    const value = parseInt(raw, 10);
+    if (value < field.min || value > field.max) {
+        throw new Error(`${field.name} value ${value} is out of range (${field.min}-${field.max})`);
+    }
+    return value;
+}
+
+function parseField(raw: string, field: FieldSpec): { values: Set<number>; restricted: boolean } {
+    const values = new Set<number>();
+    let restricted = false;
+
+    for (const part of raw.split(",")) {
+        if (part.length === 0) {
+            throw new Error(`Empty list entry in ${field.name} field`);
+        }
+
+        const [rangePart, stepPart, ...extra] = part.split("/");
+        if (extra.length > 0) {
+            throw new Error(`Invalid step in ${field.name} field "${part}"`);
+        }
+
+        let step = 1;
+        if (stepPart !== undefined) {
+            if (!/^\d+$/.test(stepPart) || parseInt(stepPart, 10) === 0) {
+                throw new Error(`Invalid step "${stepPart}" in ${field.name} field`);
+            }
+            step = parseInt(stepPart, 10);
+        }
+
+        let start = field.min;
+        let end = field.max;
+
+        if (rangePart !== "*") {
+            restricted = true;
+            const bounds = rangePart.split("-");
+            if (bounds.length > 2) {
+                throw new Error(`Invalid range in ${field.name} field "${rangePart}"`);
+            }
+            start = parseValue(bounds[0], field);
+            end = bounds.length === 2 ? parseValue(bounds[1], field) : start;
+
+            // "5/15" means "from 5 through max every 15"
+            if (bounds.length === 1 && stepPart !== undefined) {
+                end = field.max;
+            }
+
+            if (start > end) {
+                throw new Error(`Range ${rangePart} in ${field.name} field is reversed`);
+            }
+        }
+
+        for (let value = start; value <= end; value += step) {
+            values.add(value);
+        }
+    }
+
+    return { values, restricted };
+}
+
+// * parse a 5-field cron expression; throws w/ a descriptive message when invalid
+export function parseCronExpression(expression: string): CronSchedule {
+    const normalized = expression.trim().replace(/\s+/g, " ");
+    const cached = parsedCache.get(normalized);
+    if (cached) {
+        return cached;
+    }
+
+    const parts = normalized.split(" ");
+    if (parts.length !== FIELDS.length) {
+        throw new Error(
+            `Expected 5 fields (minute hour day-of-month month day-of-week), got ${parts.length}`
+        );
+    }
+
+    const [minutes, hours, daysOfMonth, months, daysOfWeek] = parts.map((part, index) =>
+        parseField(part, FIELDS[index])
+    );
+
+    // 7 is an alias for Sunday
+    if (daysOfWeek.values.delete(7)) {
+        daysOfWeek.values.add(0);
+    }
+
+    const schedule: CronSchedule = {
+        minutes: minutes.values,
+        hours: hours.values,
+        daysOfMonth: daysOfMonth.values,
+        months: months.values,
+        daysOfWeek: daysOfWeek.values,
+        dayOfMonthRestricted: daysOfMonth.restricted,
+        dayOfWeekRestricted: daysOfWeek.restricted,
+    };
+
+    parsedCache.set(normalized, schedule);
+    return schedule;
+}
+
+// * cron expression validator
+export function validateCronExpression(value: string | undefined): string | undefined {
+    if (!value || value.trim().length === 0) {
+        return "Schedule cannot be empty";
+    }
+
+    try {
+        parseCronExpression(value);
+        return undefined;
+    } catch (error) {
+        return error instanceof Error ? error.message : String(error);
+    }
+}
+
+// cron semantics: when both day fields are restricted, either may match
+function dayMatches(schedule: CronSchedule, dayOfMonth: number, dayOfWeek: number): boolean {
+    const dayOfMonthMatches = schedule.daysOfMonth.has(dayOfMonth);
+    const dayOfWeekMatches = schedule.daysOfWeek.has(dayOfWeek);
+    if (schedule.dayOfMonthRestricted && schedule.dayOfWeekRestricted) {
+        return dayOfMonthMatches || dayOfWeekMatches;
+    }
+    return dayOfMonthMatches && dayOfWeekMatches;
+}
+
+// * check whether the local wall-clock minute of date matches the expression
+// local getters keep matching correct across DST changes (skipped minutes never match)
+export function cronMatches(expression: string | CronSchedule, date: Date): boolean {
+    const schedule =
+        typeof expression === "string" ? parseCronExpression(expression) : expression;
+
+    if (
+        !schedule.minutes.has(date.getMinutes()) ||
+        !schedule.hours.has(date.getHours()) ||
+        !schedule.months.has(date.getMonth() + 1)
+    ) {
+        return false;
+    }
+
+    return dayMatches(schedule, date.getDate(), date.getDay());
+}
+
+function intersects(a: Set<number>, b: Set<number>): boolean {
+    return Array.from(a).some((value) => b.has(value));
+}
+
+// * check whether two schedules share at least one minute
+// ? day-of-month & day-of-week pairs are treated as independent (each occurs in some month)
+export function cronSchedulesOverlap(a: string | CronSchedule, b: string | CronSchedule): boolean {
+    const scheduleA = typeof a === "string" ? parseCronExpression(a) : a;
+    const scheduleB = typeof b === "string" ? parseCronExpression(b) : b;
+
+    if (
+        !intersects(scheduleA.minutes, scheduleB.minutes) ||
+        !intersects(scheduleA.hours, scheduleB.hours) ||
+        !intersects(scheduleA.months, scheduleB.months)
+    ) {
+        return false;
+    }
+
+    for (let dayOfMonth = 1; dayOfMonth <= 31; dayOfMonth++) {
+        for (let dayOfWeek = 0; dayOfWeek <= 6; dayOfWeek++) {
+            if (
+                dayMatches(scheduleA, dayOfMonth, dayOfWeek) &&
+                dayMatches(scheduleB, dayOfMonth, dayOfWeek)
+            ) {
+                return true;
+            }
+        }
+    }
+    return false;
+}
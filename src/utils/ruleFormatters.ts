@@ -1,7 +1,8 @@
 // src/utils/ruleFormatters.ts
 // Centralized rule condition formatting utilities
 
-import { ThemeRule } from "../types";
+import { RuleCondition, ThemeRule } from "../types";
+import { getScheduleKey, isWellFormedRule } from "../ruleOverlap";
 
 export type FormatMode = "compact" | "equality" | "markdown";
 
@@ -17,7 +18,9 @@ export function formatRuleConditions(
 	options: FormatOptions = { mode: "compact" }
 ): string | string[] {
 	const { mode, includeTimer = true } = options;
-	const when = rule.when;
+	// malformed settings entries have no conditions worth showing
+	const when: RuleCondition = isWellFormedRule(rule) ? rule.when : {};
+	const schedule = getScheduleKey(when);
 
 	// Build array of condition strings based on mode
 	const conditions: string[] = [];
@@ -47,6 +50,17 @@ export function formatRuleConditions(
 	if (when.viewMode) {
 		conditions.push(formatCondition("viewMode", when.viewMode, mode));
 	}
+	if (when.timeOfDay) {
+		conditions.push(formatCondition("timeOfDay", when.timeOfDay, mode));
+	}
+	if (schedule) {
+		conditions.push(formatCondition("schedule", schedule, mode));
+	}
+	if (when.custom) {
+		Object.entries(when.custom).forEach(([id, value]) => {
+			conditions.push(formatCondition(`custom.${id}`, value, mode));
+		});
+	}
 	// Timer interval handled separately since it's numeric & needs special formatting
 	if (includeTimer && when.timerInterval !== undefined) {
 		conditions.push(
@@ -96,6 +110,7 @@ function camelCaseToReadable(str: string): string {
 		debugType: "Debug Type",
 		testState: "Test State",
 		viewMode: "View Mode",
+		timeOfDay: "Time of Day",
 		timerInterval: "Timer Interval",
 		workspaceName: "Workspace Name",
 	};
@@ -104,6 +119,11 @@ function camelCaseToReadable(str: string): string {
 		return specialCases[str];
 	}
 
+	// custom provider ids are shown verbatim
+	if (str.startsWith("custom.")) {
+		return `Custom ${str.slice("custom.".length)}`;
+	}
+
 	// Default: capitalize first letter
 	return str.charAt(0).toUpperCase() + str.slice(1);
 }
@@ -2,7 +2,7 @@
 // Common rule operations extracted from multiple commands
 
 import * as vscode from "vscode";
-import { loadConfig, updateAllRules } from "../config";
+import { getActiveRuleSet, loadConfig, updateAllRules } from "../config";
 import { ThemeRule } from "../types";
 import { rulesMatchExactly } from "../ruleOverlap";
 
@@ -13,11 +13,11 @@ export async function deleteRules(indices: number[]): Promise<void> {
         return;
     }
 
-    const config = loadConfig();
+    const { rules } = getActiveRuleSet(loadConfig());
 
     // validate all indices before making changes
     for (const index of indices) {
-        if (index < 0 || index >= config.rules.length) {
+        if (index < 0 || index >= rules.length) {
             throw new Error(`Invalid rule index: ${index}`);
         }
     }
@@ -26,7 +26,7 @@ export async function deleteRules(indices: number[]): Promise<void> {
     const indicesToDelete = new Set(indices);
 
     // filter out rules at specified indices
-    const newRules = config.rules.filter((_, idx) => !indicesToDelete.has(idx));
+    const newRules = rules.filter((_, idx) => !indicesToDelete.has(idx));
 
     // single atomic update
     await updateAllRules(newRules);
@@ -34,13 +34,13 @@ export async function deleteRules(indices: number[]): Promise<void> {
 
 // * move a rule from one position to another
 export async function moveRule(fromIndex: number, toIndex: number): Promise<void> {
-    const config = loadConfig();
+    const rules = [...getActiveRuleSet(loadConfig()).rules];
 
-    if (fromIndex < 0 || fromIndex >= config.rules.length) {
+    if (fromIndex < 0 || fromIndex >= rules.length) {
         throw new Error(`Invalid source index: ${fromIndex}`);
     }
 
-    if (toIndex < 0 || toIndex > config.rules.length) {
+    if (toIndex < 0 || toIndex > rules.length) {
         throw new Error(`Invalid destination index: ${toIndex}`);
     }
 
@@ -48,12 +48,12 @@ export async function moveRule(fromIndex: number, toIndex: number): Promise<void
         return; // no-op
     }
 
-    const rule = config.rules[fromIndex];
-    config.rules.splice(fromIndex, 1);
-    config.rules.splice(toIndex, 0, rule);
+    const rule = rules[fromIndex];
+    rules.splice(fromIndex, 1);
+    rules.splice(toIndex, 0, rule);
 
     // update config w/ reordered rules using mutex
-    await updateAllRules(config.rules);
+    await updateAllRules(rules);
 }
 
 export interface RuleMove {
@@ -65,8 +65,7 @@ export interface RuleMove {
 
 // * reorder rules by applying a series of moves
 export async function reorderRules(moves: RuleMove[]): Promise<void> {
-    const config = loadConfig();
-    const newRules = [...config.rules];
+    const newRules = [...getActiveRuleSet(loadConfig()).rules];
 
     const resolveIndex = (rule?: ThemeRule, fallback?: number): number => {
         if (fallback !== undefined) {
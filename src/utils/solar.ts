@@ -0,0 +1,145 @@
+// src/utils/solar.ts
+// Sunrise/sunset calculation for time-of-day rules (no network calls)
+
+export type TimeOfDay = "day" | "night";
+
+// observer location in decimal degrees (north & east positive)
+export interface GeoLocation {
+    latitude: number;
+    longitude: number;
+}
+
+export interface SunTimes {
+    sunrise?: Date;
+    sunset?: Date;
+    // set when the sun never rises or never sets on this solar day
+    polar?: TimeOfDay;
+}
+
+export interface SolarTransition {
+    phase: TimeOfDay;
+    at: Date;
+}
+
+const MS_PER_DAY = 24 * 60 * 60 * 1000;
+const JULIAN_UNIX_EPOCH = 2440587.5;
+const JULIAN_J2000 = 2451545;
+const JULIAN_CORRECTION = 0.0009;
+const EARTH_OBLIQUITY = 23.4397; // degrees
+const EARTH_PERIHELION = 102.9372; // degrees
+const SUNRISE_ALTITUDE = -0.833; // degrees, accounts for refraction & solar disc radius
+
+const toRadians = (degrees: number) => (degrees * Math.PI) / 180;
+const toDegrees = (radians: number) => (radians * 180) / Math.PI;
+
+function toJulian(date: Date): number {
+    return date.getTime() / MS_PER_DAY + JULIAN_UNIX_EPOCH;
+}
+
+function fromJulian(julian: number): Date {
+    return new Date((julian - JULIAN_UNIX_EPOCH) * MS_PER_DAY);
+}
+
+// * validate latitude/longitude ranges & return an error message when invalid
+export function validateLocation(location: unknown): string | undefined {
+    if (!location || typeof location !== "object") {
+        return "Location must be an object with latitude and longitude";
+    }
+
+    const { latitude, longitude } = location as Partial<GeoLocation>;
+
+    if (typeof latitude !== "number" || !Number.isFinite(latitude) || Math.abs(latitude) > 90) {
+        return "Latitude must be a number between -90 and 90";
+    }
+
+    if (typeof longitude !== "number" || !Number.isFinite(longitude) || Math.abs(longitude) > 180) {
+        return "Longitude must be a number between -180 and 180";
+    }
+
+    return undefined;
+}
+
+// * compute sunrise & sunset for the solar day closest to the given instant
+// based on the standard sunrise equation (accurate to within a couple of minutes)
+export function getSunTimes(date: Date, location: GeoLocation): SunTimes {
+    const westLongitude = -location.longitude;
+    const daysSinceJ2000 = toJulian(date) - JULIAN_J2000;
+    const cycle = Math.round(daysSinceJ2000 - JULIAN_CORRECTION - westLongitude / 360);
+    const approxTransit = JULIAN_CORRECTION + westLongitude / 360 + cycle;
+
+    const meanAnomaly = (357.5291 + 0.98560028 * approxTransit) % 360;
+    const anomalyRad = toRadians(meanAnomaly);
+    const center =
+        1.9148 * Math.sin(anomalyRad) +
+        0.02 * Math.sin(2 * anomalyRad) +
+        0.0003 * Math.sin(3 * anomalyRad);
+    const eclipticLongitude = toRadians((meanAnomaly + center + 180 + EARTH_PERIHELION) % 360);
+
+    const transit =
+        JULIAN_J2000 +
+        approxTransit +
+        0.0053 * Math.sin(anomalyRad) -
+        0.0069 * Math.sin(2 * eclipticLongitude);
+
+    const declination = Math.asin(
+        Math.sin(eclipticLongitude) * Math.sin(toRadians(EARTH_OBLIQUITY))
+    );
+    const latitudeRad = toRadians(location.latitude);
+    const cosHourAngle =
+        (Math.sin(toRadians(SUNRISE_ALTITUDE)) - Math.sin(latitudeRad) * Math.sin(declination)) /
+        (Math.cos(latitudeRad) * Math.cos(declination));
+
+    if (cosHourAngle > 1) {
+        return { polar: "night" };
+    }
+    if (cosHourAngle < -1) {
+        return { polar: "day" };
+    }
+
+    const hourAngle = toDegrees(Math.acos(cosHourAngle));
+    return {
+        sunrise: fromJulian(transit - hourAngle / 360),
+        sunset: fromJulian(transit + hourAngle / 360),
+    };
+}
+
+// * determine whether the sun is up at the given instant
+export function getTimeOfDay(date: Date, location: GeoLocation): TimeOfDay {
+    const times = getSunTimes(date, location);
+    if (times.polar) {
+        return times.polar;
+    }
+
+    const now = date.getTime();
+    return now >= times.sunrise!.getTime() && now < times.sunset!.getTime() ? "day" : "night";
+}
+
+// * find the next sunrise or sunset after the given instant
+// returns undefined during polar day/night when no transition happens in the next few days
+export function getNextSolarTransition(
+    date: Date,
+    location: GeoLocation
+): SolarTransition | undefined {
+    const now = date.getTime();
+    const candidates: SolarTransition[] = [];
+
+    // scan neighbouring solar days; transitions near midnight UTC can belong to either
+    for (let offset = -1; offset <= 2; offset++) {
+        const times = getSunTimes(new Date(now + offset * MS_PER_DAY), location);
+        if (times.sunrise && times.sunrise.getTime() > now) {
+            candidates.push({ phase: "day", at: times.sunrise });
+        }
+        if (times.sunset && times.sunset.getTime() > now) {
+            candidates.push({ phase: "night", at: times.sunset });
+        }
+    }
+
+    candidates.sort((a, b) => a.at.getTime() - b.at.getTime());
+    return candidates[0];
+}
+
+// * describe a transition for display, e.g. "night at 18:42"
+export function describeSolarTransition(transition: SolarTransition): string {
+    const time = transition.at.toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" });
+    return `${transition.phase} at ${time}`;
+}
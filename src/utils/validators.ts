@@ -145,3 +145,38 @@ export function validateTimerInterval(value: string | undefined): string | undef
 
     return undefined;
 }
+
+// * parse "id=value, id2=value2" custom condition input; throws w/ a descriptive message
+export function parseCustomConditions(value: string): Record<string, string> {
+    const custom: Record<string, string> = {};
+
+    value.split(",").forEach((entry) => {
+        const separator = entry.indexOf("=");
+        const id = separator >= 0 ? entry.slice(0, separator).trim() : "";
+        const expected = separator >= 0 ? entry.slice(separator + 1).trim() : "";
+        if (!id || !expected) {
+            throw new Error(`Expected id=value, got "${entry.trim()}"`);
+        }
+        // assigning __proto__ would swap the prototype instead of adding a condition
+        if (id === "__proto__") {
+            throw new Error(`Provider id "${id}" is reserved`);
+        }
+        custom[id] = expected;
+    });
+
+    return custom;
+}
+
+// * custom conditions validator
+export function validateCustomConditions(value: string | undefined): string | undefined {
+    if (!value || value.trim().length === 0) {
+        return "Custom conditions cannot be empty";
+    }
+
+    try {
+        parseCustomConditions(value);
+        return undefined;
+    } catch (error) {
+        return error instanceof Error ? error.message : String(error);
+    }
+}